@@ -108,7 +108,24 @@ func main() {
 	}
 	rootCmd.AddCommand(confCmd)
 
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Inspect the status of the Ingresses",
+	}
+
+	statusDiffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Output the Ingresses with a status different from the addresses to publish as a JSON array",
+		Run: func(cmd *cobra.Command, args []string) {
+			statusDiff()
+		},
+	}
+	statusCmd.AddCommand(statusDiffCmd)
+
+	rootCmd.AddCommand(statusCmd)
+
 	rootCmd.PersistentFlags().IntVar(&nginx.StatusPort, "status-port", 10246, `Port to use for the lua HTTP endpoint configuration.`)
+	rootCmd.PersistentFlags().IntVar(&nginx.ProfilerPort, "profiler-port", 10245, `Port of the profiler server of the controller.`)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -236,6 +253,28 @@ func general() {
 	fmt.Println(prettyBuffer.String())
 }
 
+func statusDiff() {
+	statusCode, body, requestErr := nginx.NewGetProfilerRequest(nginx.StatusDiffPath)
+	if requestErr != nil {
+		fmt.Println(requestErr)
+		return
+	}
+	if statusCode != 200 {
+		fmt.Printf("Controller returned code %v\n", statusCode)
+		fmt.Println(string(body))
+		return
+	}
+
+	var prettyBuffer bytes.Buffer
+	indentErr := json.Indent(&prettyBuffer, body, "", "  ")
+	if indentErr != nil {
+		fmt.Println(indentErr)
+		return
+	}
+
+	fmt.Println(prettyBuffer.String())
+}
+
 func readNginxConf() {
 	conf, err := nginx.ReadNginxConf()
 	if err != nil {
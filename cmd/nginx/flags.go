@@ -116,6 +116,10 @@ Requires setting the publish-service parameter to a valid Service reference.`)
 			`Update the load-balancer status of Ingress objects when the controller shuts down.
 Requires the update-status parameter.`)
 
+		reportControllerVersion = flags.Bool("report-status-controller-version", false,
+			`Annotate the Ingress objects with the version of the controller updating their load-balancer status.
+Requires the update-status parameter.`)
+
 		useNodeInternalIP = flags.Bool("report-node-internal-ip-address", false,
 			`Set the load-balancer status of Ingress objects to internal Node addresses instead of external.
 Requires the update-status parameter.`)
@@ -287,6 +291,7 @@ https://blog.maxmind.com/2019/12/18/significant-changes-to-accessing-and-using-g
 		PublishService:             *publishSvc,
 		PublishStatusAddress:       *publishStatusAddress,
 		UpdateStatusOnShutdown:     *updateStatusOnShutdown,
+		ReportControllerVersion:    *reportControllerVersion,
 		ShutdownGracePeriod:        *shutdownGracePeriod,
 		UseNodeInternalIP:          *useNodeInternalIP,
 		SyncRateLimit:              *syncRateLimit,
@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand" // #nosec
 	"net/http"
@@ -160,18 +161,21 @@ func main() {
 	}
 	mc.Start()
 
-	if conf.EnableProfiling {
-		go registerProfiler()
-	}
+	conf.MetricsRegisterer = reg
 
 	ngx := controller.NewNGINXController(conf, mc)
 
+	if conf.EnableProfiling {
+		go registerProfiler(ngx)
+	}
+
 	mux := http.NewServeMux()
 	registerHealthz(nginx.HealthPath, ngx, mux)
 	registerMetrics(reg, mux)
 
 	go startHTTPServer(conf.ListenPorts.Health, mux)
 	go ngx.Start()
+	go handleStatusSignals(ngx)
 
 	handleSigterm(ngx, func(code int) {
 		os.Exit(code)
@@ -180,6 +184,24 @@ func main() {
 
 type exiter func(code int)
 
+// handleStatusSignals pauses the update of the Ingress status when SIGUSR1
+// is received and resumes it with SIGUSR2
+func handleStatusSignals(ngx *controller.NGINXController) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	for sig := range signalChan {
+		switch sig {
+		case syscall.SIGUSR1:
+			klog.InfoS("Received SIGUSR1, pausing Ingress status updates")
+			ngx.PauseStatusUpdates()
+		case syscall.SIGUSR2:
+			klog.InfoS("Received SIGUSR2, resuming Ingress status updates")
+			ngx.ResumeStatusUpdates()
+		}
+	}
+}
+
 func handleSigterm(ngx *controller.NGINXController, exit exiter) {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGTERM)
@@ -323,7 +345,24 @@ func registerMetrics(reg *prometheus.Registry, mux *http.ServeMux) {
 	)
 }
 
-func registerProfiler() {
+// statusDiffHandler returns the Ingresses with a status different from
+// the addresses to publish as a JSON array
+func statusDiffHandler(ic *controller.NGINXController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		diffs, err := ic.StatusDiff(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(diffs); err != nil {
+			klog.ErrorS(err, "encoding the Ingress status diff")
+		}
+	}
+}
+
+func registerProfiler(ic *controller.NGINXController) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -336,6 +375,7 @@ func registerProfiler() {
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc(nginx.StatusDiffPath, statusDiffHandler(ic))
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf("127.0.0.1:%v", nginx.ProfilerPort),
@@ -18,6 +18,8 @@ package class
 
 import (
 	networking "k8s.io/api/networking/v1beta1"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
 	"k8s.io/ingress-nginx/internal/k8s"
 )
 
@@ -26,6 +28,12 @@ const (
 	// The controller only processes Ingresses with this annotation either
 	// unset, or set to either the configured value or the empty string.
 	IngressKey = "kubernetes.io/ingress.class"
+
+	// StatusKey, without the annotations prefix, picks the class of the
+	// controller updating the status of the Ingress. When present it takes
+	// precedence over IngressKey and IngressClassName for the status, but
+	// not for routing.
+	StatusKey = "status-class"
 )
 
 var (
@@ -38,6 +46,21 @@ var (
 	IngressClass = "nginx"
 )
 
+// StatusClass returns the value of the status-class annotation
+func StatusClass(ing *networking.Ingress) (string, bool) {
+	value, ok := ing.GetAnnotations()[parser.GetAnnotationWithPrefix(StatusKey)]
+	return value, ok
+}
+
+// IsCurrent returns true if name is the class of this controller
+func IsCurrent(name string) bool {
+	if k8s.IngressClass != nil {
+		return name == k8s.IngressClass.Name
+	}
+
+	return name == IngressClass
+}
+
 // IsValid returns true if the given Ingress specify the ingress.class
 // annotation or IngressClassName resource for Kubernetes >= v1.18
 func IsValid(ing *networking.Ingress) bool {
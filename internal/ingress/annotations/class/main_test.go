@@ -101,3 +101,46 @@ func TestIsValidClass(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusClass(t *testing.T) {
+	ic := IngressClass
+	k8sic := k8s.IngressClass
+	defer func() {
+		IngressClass = ic
+		k8s.IngressClass = k8sic
+	}()
+
+	ing := &networking.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "foo",
+			Namespace: api.NamespaceDefault,
+			Annotations: map[string]string{
+				IngressKey: "nginx",
+			},
+		},
+	}
+
+	if _, ok := StatusClass(ing); ok {
+		t.Errorf("unexpected status class without the annotation")
+	}
+
+	ing.Annotations["nginx.ingress.kubernetes.io/status-class"] = "custom"
+	if statusClass, ok := StatusClass(ing); !ok || statusClass != "custom" {
+		t.Errorf("returned (%v, %v) but expected (custom, true)", statusClass, ok)
+	}
+
+	IngressClass = "nginx"
+	k8s.IngressClass = nil
+	if IsCurrent("custom") || !IsCurrent("nginx") {
+		t.Errorf("expected nginx to be the current class")
+	}
+
+	k8s.IngressClass = &networking.IngressClass{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name: "custom",
+		},
+	}
+	if !IsCurrent("custom") || IsCurrent("nginx") {
+		t.Errorf("expected custom to be the current class")
+	}
+}
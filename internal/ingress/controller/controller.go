@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/mitchellh/hashstructure"
+	"github.com/prometheus/client_golang/prometheus"
 	apiv1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1beta1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -83,6 +84,10 @@ type Configuration struct {
 	ElectionID             string
 	UpdateStatusOnShutdown bool
 
+	// ReportControllerVersion annotates the Ingresses with the version of
+	// the controller writing their status
+	ReportControllerVersion bool
+
 	ListenPorts *ngx_config.ListenPorts
 
 	DisableServiceExternalName bool
@@ -94,6 +99,8 @@ type Configuration struct {
 	EnableMetrics  bool
 	MetricsPerHost bool
 
+	MetricsRegisterer prometheus.Registerer
+
 	FakeCertificate *ingress.SSLCert
 
 	SyncRateLimit float32
@@ -110,6 +117,10 @@ type Configuration struct {
 	MonitorMaxBatchSize int
 
 	ShutdownGracePeriod int
+
+	// LeaderObserver is notified of every leader change observed in the
+	// election of the instance updating the Ingress status
+	LeaderObserver LeaderObserver
 }
 
 // GetPublishService returns the Service used to set the load-balancer status of Ingresses.
@@ -82,6 +82,10 @@ func (fis fakeIngressStore) ListIngresses() []*ingress.Ingress {
 	return fis.ingresses
 }
 
+func (fis fakeIngressStore) ListStatusIngresses() []*ingress.Ingress {
+	return fis.ingresses
+}
+
 func (fis fakeIngressStore) FilterIngresses(ingresses []*ingress.Ingress, filterFunc store.IngressFilterFunc) []*ingress.Ingress {
 	return ingresses
 }
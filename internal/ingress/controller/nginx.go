@@ -18,6 +18,7 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -39,6 +40,7 @@ import (
 	proxyproto "github.com/armon/go-proxyproto"
 	"github.com/eapache/channels"
 	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -63,6 +65,7 @@ import (
 	"k8s.io/ingress-nginx/internal/nginx"
 	"k8s.io/ingress-nginx/internal/task"
 	"k8s.io/ingress-nginx/internal/watch"
+	"k8s.io/ingress-nginx/version"
 )
 
 const (
@@ -136,14 +139,27 @@ func NewNGINXController(config *Configuration, mc metric.Collector) *NGINXContro
 	n.syncQueue = task.NewTaskQueue(n.syncIngress)
 
 	if config.UpdateStatus {
-		n.syncStatus = status.NewStatusSyncer(status.Config{
+		controllerVersion := ""
+		if config.ReportControllerVersion {
+			controllerVersion = version.RELEASE
+		}
+
+		syncStatus, err := status.NewStatusSyncer(status.Config{
 			Client:                 config.Client,
 			PublishService:         config.PublishService,
 			PublishStatusAddress:   config.PublishStatusAddress,
-			IngressLister:          n.store,
+			IngressLister:          statusIngressLister{n.store},
 			UpdateStatusOnShutdown: config.UpdateStatusOnShutdown,
 			UseNodeInternalIP:      config.UseNodeInternalIP,
+			MetricsRegisterer:      config.MetricsRegisterer,
+			WatchNamespace:         config.Namespace,
+			ControllerVersion:      controllerVersion,
 		})
+		if err != nil {
+			klog.Fatalf("Error creating the status syncer: %v", err)
+		}
+
+		n.syncStatus = syncStatus
 	} else {
 		klog.Warning("Update of Ingress status is disabled (flag --update-status)")
 	}
@@ -214,6 +230,9 @@ type NGINXController struct {
 
 	syncStatus status.Syncer
 
+	// stopLeaderElection stops the leader election, releasing the lock
+	stopLeaderElection func()
+
 	syncRateLimiter flowcontrol.RateLimiter
 
 	// stopLock is used to enforce that only a single call to Stop send at
@@ -255,30 +274,7 @@ func (n *NGINXController) Start() {
 
 	n.store.Run(n.stopCh)
 
-	// we need to use the defined ingress class to allow multiple leaders
-	// in order to update information about ingress status
-	electionID := fmt.Sprintf("%v-%v", n.cfg.ElectionID, class.DefaultClass)
-	if class.IngressClass != "" {
-		electionID = fmt.Sprintf("%v-%v", n.cfg.ElectionID, class.IngressClass)
-	}
-
-	setupLeaderElection(&leaderElectionConfig{
-		Client:     n.cfg.Client,
-		ElectionID: electionID,
-		OnStartedLeading: func(stopCh chan struct{}) {
-			if n.syncStatus != nil {
-				go n.syncStatus.Run(stopCh)
-			}
-
-			n.metricCollector.OnStartedLeading(electionID)
-			// manually update SSL expiration metrics
-			// (to not wait for a reload)
-			n.metricCollector.SetSSLExpireTime(n.runningConfig.Servers)
-		},
-		OnStoppedLeading: func() {
-			n.metricCollector.OnStoppedLeading(electionID)
-		},
-	})
+	n.startLeaderElection()
 
 	cmd := n.command.ExecCommand()
 
@@ -347,6 +343,12 @@ func (n *NGINXController) Start() {
 				}
 
 				n.syncQueue.EnqueueSkippableTask(evt.Obj)
+
+				if evt.Type == store.CreateEvent && n.syncStatus != nil {
+					if ing, ok := evt.Obj.(*networking.Ingress); ok {
+						n.syncStatus.EnqueueIngress(ing)
+					}
+				}
 			} else {
 				klog.Warningf("Unexpected event type received %T", event)
 			}
@@ -356,6 +358,74 @@ func (n *NGINXController) Start() {
 	}
 }
 
+// startLeaderElection starts the leader election. The leader updates the
+// status of the Ingresses, when enabled, and reports the leader metrics.
+func (n *NGINXController) startLeaderElection() {
+	// we need to use the defined ingress class to allow multiple leaders
+	// in order to update information about ingress status
+	electionID := fmt.Sprintf("%v-%v", n.cfg.ElectionID, class.DefaultClass)
+	if class.IngressClass != "" {
+		electionID = fmt.Sprintf("%v-%v", n.cfg.ElectionID, class.IngressClass)
+	}
+
+	n.stopLeaderElection = setupLeaderElection(&leaderElectionConfig{
+		Client:     n.cfg.Client,
+		ElectionID: electionID,
+		OnStartedLeading: func(stopCh chan struct{}) {
+			if n.syncStatus != nil {
+				go n.syncStatus.Run(stopCh)
+			}
+
+			n.metricCollector.OnStartedLeading(electionID)
+			// manually update SSL expiration metrics
+			// (to not wait for a reload)
+			n.metricCollector.SetSSLExpireTime(n.runningConfig.Servers)
+		},
+		OnStoppedLeading: func() {
+			n.metricCollector.OnStoppedLeading(electionID)
+		},
+		OnNewLeader: func(identity string) {
+			if n.cfg.LeaderObserver != nil {
+				n.cfg.LeaderObserver.OnNewLeader(identity)
+			}
+		},
+	})
+}
+
+// PauseStatusUpdates suspends the update of the Ingress status
+func (n *NGINXController) PauseStatusUpdates() {
+	if n.syncStatus != nil {
+		n.syncStatus.Pause()
+	}
+}
+
+// ResumeStatusUpdates restarts the update of the Ingress status
+func (n *NGINXController) ResumeStatusUpdates() {
+	if n.syncStatus != nil {
+		n.syncStatus.Resume()
+	}
+}
+
+// StatusUpdateErrors returns the last error updating the status of each
+// Ingress, indexed by namespace/name
+func (n *NGINXController) StatusUpdateErrors() map[string]status.UpdateError {
+	if n.syncStatus == nil {
+		return map[string]status.UpdateError{}
+	}
+
+	return n.syncStatus.UpdateErrors()
+}
+
+// StatusDiff returns the Ingresses with a status different from the
+// addresses to publish, without updating them
+func (n *NGINXController) StatusDiff(ctx context.Context) ([]status.IngressStatusDiff, error) {
+	if n.syncStatus == nil {
+		return nil, fmt.Errorf("the update of the Ingress status is disabled")
+	}
+
+	return n.syncStatus.SyncDiff(ctx)
+}
+
 // Stop gracefully stops the NGINX master process.
 func (n *NGINXController) Stop() error {
 	n.isShuttingDown = true
@@ -367,13 +437,28 @@ func (n *NGINXController) Stop() error {
 		return fmt.Errorf("shutdown already in progress")
 	}
 
+	// stop publishing the address of this pod while it still serves traffic
+	if n.syncStatus != nil {
+		if err := n.syncStatus.PrepareShutdown(); err != nil {
+			klog.ErrorS(err, "Error removing the terminating pod from the Ingress status")
+		}
+	}
+
 	time.Sleep(time.Duration(n.cfg.ShutdownGracePeriod) * time.Second)
 
 	klog.InfoS("Shutting down controller queues")
 	close(n.stopCh)
 	go n.syncQueue.Shutdown()
 	if n.syncStatus != nil {
-		n.syncStatus.Shutdown()
+		if err := n.syncStatus.Shutdown(); err != nil {
+			klog.ErrorS(err, "Error clearing the Ingress status")
+		}
+	}
+
+	// release the leadership after clearing the status
+	if n.stopLeaderElection != nil {
+		klog.InfoS("Releasing leader election lock")
+		n.stopLeaderElection()
 	}
 
 	if n.validationWebhookServer != nil {
@@ -19,8 +19,11 @@ package controller
 import (
 	"context"
 	"os"
+	"sync"
 	"time"
 
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/ingress/controller/store"
 	"k8s.io/ingress-nginx/internal/k8s"
 	"k8s.io/klog/v2"
 
@@ -33,6 +36,17 @@ import (
 	"k8s.io/client-go/tools/record"
 )
 
+// LeaderObserver is notified of the leader changes
+type LeaderObserver interface {
+	// OnNewLeader is called with the identity of the new leader, including
+	// the changes where this instance is not the old or the new leader
+	OnNewLeader(identity string)
+}
+
+// leaderReleaseTimeout is the max time to wait for the release of the
+// leader election lock when the election is stopped
+const leaderReleaseTimeout = 5 * time.Second
+
 type leaderElectionConfig struct {
 	Client clientset.Interface
 
@@ -40,14 +54,26 @@ type leaderElectionConfig struct {
 
 	OnStartedLeading func(chan struct{})
 	OnStoppedLeading func()
+	OnNewLeader      func(identity string)
 }
 
-func setupLeaderElection(config *leaderElectionConfig) {
+// setupLeaderElection starts the leader election. The returned function
+// stops the election, releasing the lock if this instance is the leader so
+// another instance takes over without waiting for the lock to expire.
+func setupLeaderElection(config *leaderElectionConfig) func() {
 	var elector *leaderelection.LeaderElector
 
-	// start a new context
-	ctx := context.Background()
+	// start a new context, canceled when the election is stopped
+	ctx, stop := context.WithCancel(context.Background())
 
+	// stopped is closed when the elector returns after the election is stopped
+	stopped := make(chan struct{})
+	var stoppedOnce sync.Once
+
+	// mu protects stopCh and cancelContext, used by the callbacks of the
+	// elector restarted after each loss of the leadership
+	var mu sync.Mutex
+	var stopCh chan struct{}
 	var cancelContext context.CancelFunc
 
 	var newLeaderCtx = func(ctx context.Context) context.CancelFunc {
@@ -57,31 +83,54 @@ func setupLeaderElection(config *leaderElectionConfig) {
 		return cancel
 	}
 
-	var stopCh chan struct{}
 	callbacks := leaderelection.LeaderCallbacks{
 		OnStartedLeading: func(ctx context.Context) {
 			klog.V(2).InfoS("I am the new leader")
+			mu.Lock()
 			stopCh = make(chan struct{})
+			leaderStopCh := stopCh
+			mu.Unlock()
 
 			if config.OnStartedLeading != nil {
-				config.OnStartedLeading(stopCh)
+				config.OnStartedLeading(leaderStopCh)
 			}
 		},
 		OnStoppedLeading: func() {
 			klog.V(2).InfoS("I am not leader anymore")
-			close(stopCh)
+			electionStopped := ctx.Err() != nil
+
+			mu.Lock()
+			// the elector also returns without acquiring the leadership
+			// when the election is stopped
+			if stopCh != nil {
+				close(stopCh)
+				stopCh = nil
+			}
 
 			// cancel the context
 			cancelContext()
 
-			cancelContext = newLeaderCtx(ctx)
+			if !electionStopped {
+				cancelContext = newLeaderCtx(ctx)
+			}
+			mu.Unlock()
 
 			if config.OnStoppedLeading != nil {
 				config.OnStoppedLeading()
 			}
+
+			if electionStopped {
+				stoppedOnce.Do(func() {
+					close(stopped)
+				})
+			}
 		},
 		OnNewLeader: func(identity string) {
 			klog.InfoS("New leader elected", "identity", identity)
+
+			if config.OnNewLeader != nil {
+				config.OnNewLeader(identity)
+			}
 		},
 	}
 
@@ -110,11 +159,38 @@ func setupLeaderElection(config *leaderElectionConfig) {
 		RenewDeadline: ttl / 2,
 		RetryPeriod:   ttl / 4,
 
+		// the lock is released when the election is stopped
+		ReleaseOnCancel: true,
+
 		Callbacks: callbacks,
 	})
 	if err != nil {
 		klog.Fatalf("unexpected error starting leader election: %v", err)
 	}
 
+	mu.Lock()
 	cancelContext = newLeaderCtx(ctx)
+	mu.Unlock()
+
+	return func() {
+		stop()
+
+		select {
+		case <-stopped:
+			klog.InfoS("Leader election stopped")
+		case <-time.After(leaderReleaseTimeout):
+			klog.Warningf("timeout waiting for the release of the leader election lock %v", config.ElectionID)
+		}
+	}
+}
+
+// statusIngressLister lists the Ingresses whose status is updated by the controller
+type statusIngressLister struct {
+	store store.Storer
+}
+
+// ListIngresses returns the Ingresses of the store and the Ingresses of other
+// classes with a status-class annotation matching the class of the controller
+func (l statusIngressLister) ListIngresses() []*ingress.Ingress {
+	return l.store.ListStatusIngresses()
 }
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/ingress/metric"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+type fakeLeaderObserver struct {
+	leaders chan string
+}
+
+func (o *fakeLeaderObserver) OnNewLeader(identity string) {
+	o.leaders <- identity
+}
+
+func TestLeaderObserver(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-nginx-controller-1",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	observer := &fakeLeaderObserver{leaders: make(chan string, 1)}
+	started := make(chan struct{}, 1)
+
+	stop := setupLeaderElection(&leaderElectionConfig{
+		Client:     testclient.NewSimpleClientset(),
+		ElectionID: "ingress-controller-leader",
+		OnStartedLeading: func(stopCh chan struct{}) {
+			started <- struct{}{}
+		},
+		OnNewLeader: observer.OnNewLeader,
+	})
+	defer stop()
+
+	select {
+	case identity := <-observer.leaders:
+		if identity != "ingress-nginx-controller-1" {
+			t.Errorf("returned %v but expected %v", identity, "ingress-nginx-controller-1")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the observer to be notified of the new leader")
+	}
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the instance to start leading")
+	}
+}
+
+func leaderElectionRecord(t *testing.T, client *testclient.Clientset, name string) resourcelock.LeaderElectionRecord {
+	cm, err := client.CoreV1().ConfigMaps(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var record resourcelock.LeaderElectionRecord
+	if err := json.Unmarshal([]byte(cm.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]), &record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return record
+}
+
+func TestStopLeaderElection(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-nginx-controller-1",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	client := testclient.NewSimpleClientset()
+	started := make(chan chan struct{}, 1)
+	stoppedLeading := make(chan struct{}, 1)
+
+	stop := setupLeaderElection(&leaderElectionConfig{
+		Client:     client,
+		ElectionID: "ingress-controller-leader",
+		OnStartedLeading: func(stopCh chan struct{}) {
+			started <- stopCh
+		},
+		OnStoppedLeading: func() {
+			stoppedLeading <- struct{}{}
+		},
+	})
+
+	var leaderStopCh chan struct{}
+	select {
+	case leaderStopCh = <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the instance to start leading")
+	}
+
+	record := leaderElectionRecord(t, client, "ingress-controller-leader")
+	if record.HolderIdentity != "ingress-nginx-controller-1" {
+		t.Errorf("returned %v but expected %v", record.HolderIdentity, "ingress-nginx-controller-1")
+	}
+
+	start := time.Now()
+	stop()
+	if elapsed := time.Since(start); elapsed >= leaderReleaseTimeout {
+		t.Errorf("expected the election to stop before the timeout but it took %v", elapsed)
+	}
+
+	// the lock is released without waiting for the lease to expire
+	record = leaderElectionRecord(t, client, "ingress-controller-leader")
+	if record.HolderIdentity != "" {
+		t.Errorf("expected an empty holder identity but returned %v", record.HolderIdentity)
+	}
+
+	select {
+	case <-leaderStopCh:
+	default:
+		t.Errorf("expected the leader stop channel to be closed")
+	}
+
+	select {
+	case <-stoppedLeading:
+	default:
+		t.Errorf("expected the stop of the leadership to be notified")
+	}
+}
+
+// fakeLeaderCollector records the leadership changes reported to the metrics
+type fakeLeaderCollector struct {
+	metric.DummyCollector
+
+	started chan string
+	stopped chan string
+}
+
+func (c *fakeLeaderCollector) OnStartedLeading(electionID string) {
+	c.started <- electionID
+}
+
+func (c *fakeLeaderCollector) OnStoppedLeading(electionID string) {
+	c.stopped <- electionID
+}
+
+func TestLeaderElectionStatusUpdateDisabled(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-nginx-controller-1",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	client := testclient.NewSimpleClientset()
+	collector := &fakeLeaderCollector{
+		started: make(chan string, 1),
+		stopped: make(chan string, 1),
+	}
+	n := &NGINXController{
+		cfg: &Configuration{
+			Client:       client,
+			ElectionID:   "ingress-controller-leader",
+			UpdateStatus: false,
+		},
+		runningConfig:   &ingress.Configuration{},
+		metricCollector: collector,
+	}
+
+	// the election keeps running for the metrics of the leader
+	n.startLeaderElection()
+
+	select {
+	case <-collector.started:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the metrics to be notified of the leadership")
+	}
+
+	n.stopLeaderElection()
+
+	select {
+	case <-collector.stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the metrics to be notified of the end of the leadership")
+	}
+
+	if n.syncStatus != nil {
+		t.Errorf("expected no status syncer")
+	}
+
+	for _, action := range client.Actions() {
+		if action.GetResource().Resource == "ingresses" {
+			t.Errorf("unexpected request %v %v", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+}
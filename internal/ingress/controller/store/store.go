@@ -81,6 +81,11 @@ type Storer interface {
 	// ListIngresses returns a list of all Ingresses in the store.
 	ListIngresses() []*ingress.Ingress
 
+	// ListStatusIngresses returns the Ingresses in the store and the
+	// Ingresses of other classes with a status-class annotation
+	// matching the class of the controller.
+	ListStatusIngresses() []*ingress.Ingress
+
 	// GetLocalSSLCert returns the local copy of a SSLCert
 	GetLocalSSLCert(name string) (*ingress.SSLCert, error)
 
@@ -797,6 +802,24 @@ func (s *k8sStore) ListIngresses() []*ingress.Ingress {
 	return ingresses
 }
 
+// ListStatusIngresses returns the Ingresses in the store and the Ingresses of
+// other classes with a status-class annotation matching the class of the controller
+func (s *k8sStore) ListStatusIngresses() []*ingress.Ingress {
+	ingresses := s.ListIngresses()
+	for _, item := range s.listers.Ingress.List() {
+		ing := item.(*networkingv1beta1.Ingress)
+		if class.IsValid(ing) {
+			continue
+		}
+
+		if statusClass, ok := class.StatusClass(ing); ok && class.IsCurrent(statusClass) {
+			ingresses = append(ingresses, &ingress.Ingress{Ingress: *ing})
+		}
+	}
+
+	return ingresses
+}
+
 // GetLocalSSLCert returns the local copy of a SSLCert
 func (s *k8sStore) GetLocalSSLCert(key string) (*ingress.SSLCert, error) {
 	return s.sslStore.ByKey(key)
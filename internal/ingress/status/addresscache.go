@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// runningAddressCache keeps the last running addresses computed without
+// error, to keep publishing them while the API server is unavailable
+type runningAddressCache struct {
+	lock sync.Mutex
+
+	clock clock.Clock
+
+	addrs   []string
+	updated time.Time
+}
+
+func newRunningAddressCache(c clock.Clock) *runningAddressCache {
+	return &runningAddressCache{clock: c}
+}
+
+// set saves the running addresses computed in a sync
+func (c *runningAddressCache) set(addrs []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.addrs = append([]string{}, addrs...)
+	c.updated = c.clock.Now()
+}
+
+// get returns the saved running addresses if they were computed less than
+// ttl ago
+func (c *runningAddressCache) get(ttl time.Duration) ([]string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.updated.IsZero() || c.clock.Since(c.updated) >= ttl {
+		return nil, false
+	}
+
+	return append([]string{}, c.addrs...), true
+}
+
+// cachedRunningAddresses returns the last running addresses computed
+// without error, when the error obtaining them is transient and they were
+// computed less than RunningAddressCacheTTL ago
+func (s *statusSync) cachedRunningAddresses(err error) ([]string, bool) {
+	if s.RunningAddressCacheTTL <= 0 || s.addressCache == nil || !isRetryableError(err) {
+		return nil, false
+	}
+
+	return s.addressCache.get(s.RunningAddressCacheTTL)
+}
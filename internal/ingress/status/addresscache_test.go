@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRunningAddressCache(t *testing.T) {
+	client := testclient.NewSimpleClientset(buildServiceWithPorts(), &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = "default/foo_ports"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.RunningAddressCacheTTL = time.Minute
+	fk.addressCache = newRunningAddressCache(fakeClock)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+	if status := getIngressStatus(t, client, "foo_ingress"); !reflect.DeepEqual(status, expected) {
+		t.Fatalf("returned %v but expected %v", status, expected)
+	}
+
+	// the API server is unavailable
+	client.PrependReactor("get", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable(fmt.Sprintf("unable to reach %v", action.GetResource().Resource))
+	})
+
+	fakeClock.Step(30 * time.Second)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("expected the cached addresses to be used but returned %v", err)
+	}
+	if state := fk.AddressState(); state != AddressStatePopulated {
+		t.Errorf("returned %v but expected %v", state, AddressStatePopulated)
+	}
+	if status := getIngressStatus(t, client, "foo_ingress"); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+
+	// the cached addresses expire
+	fakeClock.Step(time.Minute)
+
+	if _, err := fk.reconcile(context.TODO()); err == nil {
+		t.Errorf("expected an error after the cached addresses expire")
+	}
+	if status := getIngressStatus(t, client, "foo_ingress"); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestRunningAddressCacheDisabled(t *testing.T) {
+	fk := buildStatusSync()
+	fk.addressCache = newRunningAddressCache(clock.RealClock{})
+	fk.addressCache.set([]string{"10.0.0.1"})
+
+	if addrs, ok := fk.cachedRunningAddresses(fmt.Errorf("connection refused")); ok {
+		t.Errorf("expected no cached addresses without a TTL but returned %v", addrs)
+	}
+
+	// non transient errors do not use the cached addresses
+	fk.RunningAddressCacheTTL = time.Minute
+	if addrs, ok := fk.cachedRunningAddresses(apierrors.NewForbidden(apiv1.Resource("services"), "foo", fmt.Errorf("not allowed"))); ok {
+		t.Errorf("expected no cached addresses for a forbidden error but returned %v", addrs)
+	}
+
+	if _, ok := fk.cachedRunningAddresses(fmt.Errorf("connection refused")); !ok {
+		t.Errorf("expected the cached addresses for a transient error")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync/atomic"
+)
+
+// AddressState describes the running addresses computed by the last sync
+type AddressState int32
+
+const (
+	// AddressStateUnknown means the running addresses were not computed yet,
+	// or the last attempt to compute them failed
+	AddressStateUnknown AddressState = iota
+	// AddressStateEmpty means the controller is not running in any address
+	AddressStateEmpty
+	// AddressStatePopulated means the controller is running in at least
+	// one address
+	AddressStatePopulated
+)
+
+func (st AddressState) String() string {
+	switch st {
+	case AddressStateEmpty:
+		return "Empty"
+	case AddressStatePopulated:
+		return "Populated"
+	default:
+		return "Unknown"
+	}
+}
+
+// AddressState returns the state of the running addresses computed by the
+// last sync
+func (s *statusSync) AddressState() AddressState {
+	return AddressState(atomic.LoadInt32(&s.addressState))
+}
+
+// setAddressState updates the state with the result of runningAddresses
+func (s *statusSync) setAddressState(addrs []string, err error) {
+	state := AddressStatePopulated
+	switch {
+	case err != nil:
+		state = AddressStateUnknown
+	case len(addrs) == 0:
+		state = AddressStateEmpty
+	}
+
+	atomic.StoreInt32(&s.addressState, int32(state))
+}
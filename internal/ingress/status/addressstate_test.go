@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+func TestAddressState(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels:    map[string]string{"app": "no-pods"},
+		},
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+
+	statusOf := func(name string) []apiv1.LoadBalancerIngress {
+		ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return ing.Status.LoadBalancer.Ingress
+	}
+
+	if state := fk.AddressState(); state != AddressStateUnknown {
+		t.Errorf("returned %v but expected %v", state, AddressStateUnknown)
+	}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := fk.AddressState(); state != AddressStatePopulated {
+		t.Errorf("returned %v but expected %v", state, AddressStatePopulated)
+	}
+	if status := statusOf("foo_ingress_1"); len(status) != 1 {
+		t.Errorf("expected one address but returned %v", status)
+	}
+
+	// no pod of the controller is running
+	fk.PublishStatusAddress = ""
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := fk.AddressState(); state != AddressStateEmpty {
+		t.Errorf("returned %v but expected %v", state, AddressStateEmpty)
+	}
+	if status := statusOf("foo_ingress_1"); len(status) != 0 {
+		t.Errorf("expected an empty status but returned %v", status)
+	}
+
+	// the addresses of the publish service cannot be obtained
+	fk.PublishService = apiv1.NamespaceDefault + "/" + "foo"
+	if _, err := fk.reconcile(context.TODO()); err == nil {
+		t.Errorf("expected an error obtaining the running addresses")
+	}
+	if state := fk.AddressState(); state != AddressStateUnknown {
+		t.Errorf("returned %v but expected %v", state, AddressStateUnknown)
+	}
+}
+
+func TestEmptyStatusNotConfirmed(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "lb.example.com"
+	fk.AllowedHostnameSuffixes = []string{"example.org"}
+
+	// the running addresses are not empty but none is allowed
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 0 {
+		t.Errorf("expected no updated Ingress but returned %+v", r)
+	}
+	if state := fk.AddressState(); state != AddressStatePopulated {
+		t.Errorf("returned %v but expected %v", state, AddressStatePopulated)
+	}
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ing.Status.LoadBalancer.Ingress) == 0 {
+		t.Errorf("expected the status to be kept")
+	}
+}
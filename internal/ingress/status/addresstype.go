@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// publishAddressTypeAnnotation is the annotation overriding the type of the
+// node addresses published in the status of an Ingress
+const publishAddressTypeAnnotation = "publish-address-type"
+
+var nodeAddressTypes = []apiv1.NodeAddressType{
+	apiv1.NodeHostName,
+	apiv1.NodeExternalIP,
+	apiv1.NodeInternalIP,
+	apiv1.NodeExternalDNS,
+	apiv1.NodeInternalDNS,
+}
+
+// parseNodeAddressType returns the NodeAddressType named value
+func parseNodeAddressType(value string) (apiv1.NodeAddressType, error) {
+	for _, addressType := range nodeAddressTypes {
+		if strings.EqualFold(value, string(addressType)) {
+			return addressType, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid node address type %q (expected one of %v)", value, nodeAddressTypes)
+}
+
+// ingressAddressType returns the type of the node addresses to publish in the
+// status of the Ingress, set with the publish-address-type annotation.
+// Returns false if the annotation is not set or the addresses are not
+// obtained from the nodes.
+func (s *statusSync) ingressAddressType(ing *networking.Ingress) (apiv1.NodeAddressType, bool) {
+	value, ok := ing.Annotations[parser.GetAnnotationWithPrefix(publishAddressTypeAnnotation)]
+	if !ok {
+		return "", false
+	}
+
+	if !s.usesNodeAddresses() {
+		klog.V(3).InfoS("ignoring publish address type (the status does not contain node addresses)", "namespace", ing.Namespace, "ingress", ing.Name)
+		return "", false
+	}
+
+	addressType, err := parseNodeAddressType(strings.TrimSpace(value))
+	if err != nil {
+		klog.Warningf("ignoring publish address type of Ingress %v/%v: %v", ing.Namespace, ing.Name, err)
+		return "", false
+	}
+
+	return addressType, true
+}
+
+// addressTypeStatus keeps the status computed for each node address type
+// during a sync
+type addressTypeStatus map[apiv1.NodeAddressType][]apiv1.LoadBalancerIngress
+
+// ingressAddresses returns the addresses to publish in the status of the
+// Ingress: the given status, or the node addresses of the type set with the
+// publish-address-type annotation. The node addresses are only health checked
+// when probe is true.
+func (s *statusSync) ingressAddresses(ctx context.Context, ing *networking.Ingress, status []apiv1.LoadBalancerIngress,
+	cache addressTypeStatus, probe bool) ([]apiv1.LoadBalancerIngress, error) {
+	addressType, ok := s.ingressAddressType(ing)
+	if !ok {
+		return status, nil
+	}
+
+	if cached, ok := cache[addressType]; ok {
+		return cached, nil
+	}
+
+	addrs, err := s.nodeRunningAddresses([]apiv1.NodeAddressType{addressType})
+	if err != nil {
+		return nil, err
+	}
+
+	addrs = s.allowedAddresses(addrs)
+	if probe {
+		addrs = s.healthyAddresses(addrs)
+	}
+
+	typeStatus := s.rewriteHostnames(sliceToStatus(addrs))
+	if s.NormalizeStatusEntries {
+		typeStatus = normalizeLoadBalancerIngress(typeStatus)
+	}
+	s.sortStatus(typeStatus)
+
+	cache[addressType] = typeStatus
+	return typeStatus, nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// addAddressTypeIngresses creates an Ingress for each publish address type
+// annotation value, or without the annotation if the value is empty
+func addAddressTypeIngresses(t *testing.T, client *testclient.Clientset, values map[string]string) {
+	for name, value := range values {
+		ing := &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: apiv1.NamespaceDefault,
+			},
+		}
+		if value != "" {
+			ing.Annotations = map[string]string{
+				parser.GetAnnotationWithPrefix(publishAddressTypeAnnotation): value,
+			}
+		}
+
+		_, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Create(context.TODO(), ing, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestPublishAddressTypeAnnotation(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	client := buildSimpleClientSet()
+	addAddressTypeIngresses(t, client, map[string]string{
+		"foo_ingress_default":  "",
+		"foo_ingress_internal": "InternalIP",
+		"foo_ingress_external": "externalip",
+		"foo_ingress_invalid":  "PublicIP",
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.IngressLister = &clientIngressLister{client}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fooTests := []struct {
+		name     string
+		expected string
+	}{
+		{"foo_ingress_default", "11.0.0.2"},
+		{"foo_ingress_internal", "11.0.0.1"},
+		{"foo_ingress_external", "11.0.0.2"},
+		{"foo_ingress_invalid", "11.0.0.2"},
+	}
+
+	for _, fooTest := range fooTests {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), fooTest.name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		lbi := ing.Status.LoadBalancer.Ingress
+		if len(lbi) != 1 || lbi[0].IP != fooTest.expected {
+			t.Errorf("%v: returned %v but expected %v", fooTest.name, lbi, fooTest.expected)
+		}
+	}
+
+	// the annotation overrides the global address type
+	fk.PublishAddressTypes = []apiv1.NodeAddressType{apiv1.NodeInternalIP}
+	diffs, err := fk.SyncDiff(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changed := map[string]bool{}
+	for _, diff := range diffs {
+		changed[diff.Name] = true
+	}
+	if changed["foo_ingress_internal"] || changed["foo_ingress_external"] || !changed["foo_ingress_default"] {
+		t.Errorf("unexpected Ingresses with a different status %v", diffs)
+	}
+
+	// the annotation is ignored if the status does not contain node addresses
+	fk.PublishStatusAddress = "10.0.0.9"
+	if _, ok := fk.ingressAddressType(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				parser.GetAnnotationWithPrefix(publishAddressTypeAnnotation): "InternalIP",
+			},
+		},
+	}); ok {
+		t.Errorf("expected the publish address type to be ignored")
+	}
+}
+
+func TestParseNodeAddressType(t *testing.T) {
+	fooTests := []struct {
+		value    string
+		expected apiv1.NodeAddressType
+		er       bool
+	}{
+		{"InternalIP", apiv1.NodeInternalIP, false},
+		{"externalip", apiv1.NodeExternalIP, false},
+		{"Hostname", apiv1.NodeHostName, false},
+		{"InternalDNS", apiv1.NodeInternalDNS, false},
+		{"ExternalDNS", apiv1.NodeExternalDNS, false},
+		{"PublicIP", "", true},
+		{"", "", true},
+	}
+
+	for _, fooTest := range fooTests {
+		addressType, err := parseNodeAddressType(fooTest.value)
+		if (err != nil) != fooTest.er {
+			t.Errorf("%q: unexpected error %v", fooTest.value, err)
+		}
+		if addressType != fooTest.expected {
+			t.Errorf("%q: returned %v but expected %v", fooTest.value, addressType, fooTest.expected)
+		}
+	}
+}
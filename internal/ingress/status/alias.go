@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// statusAliasAnnotation is the annotation, without prefix, containing the
+// namespace/name of the primary Ingress whose status is mirrored by an alias
+const statusAliasAnnotation = "status-alias-of"
+
+// aliasOf returns the namespace/name of the primary Ingress of an alias
+// Ingress. Returns false if the Ingress is not an alias.
+func aliasOf(ing *ingress.Ingress) (string, bool) {
+	value := strings.TrimSpace(ing.Annotations[parser.GetAnnotationWithPrefix(statusAliasAnnotation)])
+	return value, value != ""
+}
+
+// ingressIndex finds Ingresses by namespace/name. The Ingresses are listed
+// on the first lookup, so syncs without aliases do not build the index.
+type ingressIndex struct {
+	list func() []*ingress.Ingress
+
+	ingresses map[string]*ingress.Ingress
+}
+
+func newIngressIndex(list func() []*ingress.Ingress) *ingressIndex {
+	return &ingressIndex{list: list}
+}
+
+func (i *ingressIndex) get(key string) (*ingress.Ingress, bool) {
+	if i.ingresses == nil {
+		i.ingresses = map[string]*ingress.Ingress{}
+		for _, ing := range i.list() {
+			i.ingresses[fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)] = ing
+		}
+	}
+
+	ing, ok := i.ingresses[key]
+	return ing, ok
+}
+
+// statusSource returns the Ingress used to compute the status of ing: the
+// primary Ingress for aliases, following chains of aliases, or ing itself.
+// Returns an error if a primary does not exist, is not claimed by the
+// controller, or the aliases contain a cycle.
+func (s *statusSync) statusSource(ing *ingress.Ingress, index *ingressIndex) (*ingress.Ingress, error) {
+	key := fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)
+	visited := map[string]bool{key: true}
+
+	source := ing
+	for {
+		primaryKey, ok := aliasOf(source)
+		if !ok {
+			break
+		}
+
+		if _, _, err := k8s.ParseNameNS(primaryKey); err != nil {
+			return nil, fmt.Errorf("invalid primary Ingress %q of alias Ingress %v: %v", primaryKey, key, err)
+		}
+
+		if visited[primaryKey] {
+			return nil, fmt.Errorf("cycle in the status aliases of Ingress %v at %v", key, primaryKey)
+		}
+		visited[primaryKey] = true
+
+		primary, ok := index.get(primaryKey)
+		if !ok {
+			return nil, fmt.Errorf("primary Ingress %v of alias Ingress %v does not exist", primaryKey, key)
+		}
+
+		if !s.isClaimed(primary) {
+			return nil, fmt.Errorf("primary Ingress %v of alias Ingress %v is not claimed by this controller", primaryKey, key)
+		}
+
+		source = primary
+	}
+
+	return source, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// buildAliasIngress returns an Ingress mirroring the status of primary
+func buildAliasIngress(name, primary string) *networking.Ingress {
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: apiv1.NamespaceDefault,
+			Annotations: map[string]string{
+				parser.GetAnnotationWithPrefix(statusAliasAnnotation): primary,
+			},
+		},
+	}
+}
+
+// buildAliasClientSet returns a primary Ingress with a wildcard host, so its
+// status differs from the status of other Ingresses, and its aliases
+func buildAliasClientSet(objects ...runtime.Object) *testclient.Clientset {
+	primary := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_primary",
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{{Host: "*.apps.example.com"}},
+		},
+	}
+
+	objects = append(objects,
+		primary,
+		buildAliasIngress("foo_alias", "default/foo_primary"),
+		buildAliasIngress("foo_alias_of_alias", "default/foo_alias"),
+	)
+
+	return testclient.NewSimpleClientset(objects...)
+}
+
+func TestStatusAlias(t *testing.T) {
+	client := buildAliasClientSet(
+		buildAliasIngress("foo_alias_missing", "default/foo_missing"),
+		buildAliasIngress("foo_alias_cycle_1", "default/foo_alias_cycle_2"),
+		buildAliasIngress("foo_alias_cycle_2", "default/foo_alias_cycle_1"),
+		buildAliasIngress("foo_alias_invalid", "foo_primary"),
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishWildcardHostnames = true
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 3 || r.failed != 4 || r.retryable != 0 {
+		t.Errorf("expected three updated and four failed Ingresses but returned %+v", r)
+	}
+
+	mirrored := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "apps.example.com"}}
+	fooTests := []struct {
+		name     string
+		expected []apiv1.LoadBalancerIngress
+	}{
+		{"foo_primary", mirrored},
+		{"foo_alias", mirrored},
+		{"foo_alias_of_alias", mirrored},
+		{"foo_alias_missing", nil},
+		{"foo_alias_cycle_1", nil},
+		{"foo_alias_cycle_2", nil},
+		{"foo_alias_invalid", nil},
+	}
+
+	for _, fooTest := range fooTests {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), fooTest.name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, fooTest.expected) {
+			t.Errorf("%v: returned %v but expected %v", fooTest.name, ing.Status.LoadBalancer.Ingress, fooTest.expected)
+		}
+	}
+
+	errs := fk.UpdateErrors()
+	for key, expected := range map[string]string{
+		"default/foo_alias_missing": "does not exist",
+		"default/foo_alias_cycle_1": "cycle",
+		"default/foo_alias_cycle_2": "cycle",
+		"default/foo_alias_invalid": "invalid primary",
+	} {
+		if !strings.Contains(errs[key].Message, expected) {
+			t.Errorf("%v: expected an error containing %q but returned %q", key, expected, errs[key].Message)
+		}
+	}
+}
+
+func TestStatusAliasBackfill(t *testing.T) {
+	client := buildAliasClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishWildcardHostnames = true
+	fk.lastStatus.set([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+
+	// the status of a single alias is synced from the primary
+	if _, err := fk.syncIngress(context.TODO(), ingressKey("default/foo_alias")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_alias", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "apps.example.com"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
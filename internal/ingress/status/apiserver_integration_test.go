@@ -0,0 +1,335 @@
+//go:build integration
+// +build integration
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ingressesPath is the path of the Ingress API served by fakeAPIServer
+const ingressesPath = "/apis/networking.k8s.io/v1beta1/"
+
+// fakeAPIServer is a minimal API server storing Ingresses. Unlike the fake
+// clientset, the updates of the status are rejected with a conflict when the
+// resourceVersion is stale, and the patches are applied to the stored object.
+// The tests using it run with: go test -tags integration ./internal/ingress/status/
+type fakeAPIServer struct {
+	t *testing.T
+
+	lock sync.Mutex
+
+	resourceVersion int64
+	ingresses       map[string]*networking.Ingress
+
+	// beforeUpdateStatus is called with the stored Ingress before its
+	// status is updated, to simulate a concurrent change
+	beforeUpdateStatus func(ing *networking.Ingress)
+}
+
+// newFakeAPIServer starts an API server with the Ingresses and returns a
+// client connected to it. The server is stopped at the end of the test.
+func newFakeAPIServer(t *testing.T, ings ...*networking.Ingress) (*fakeAPIServer, clientset.Interface) {
+	s := &fakeAPIServer{
+		t:         t,
+		ingresses: map[string]*networking.Ingress{},
+	}
+
+	for _, ing := range ings {
+		ing = ing.DeepCopy()
+		s.bump(ing)
+		s.ingresses[ing.Namespace+"/"+ing.Name] = ing
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(server.Close)
+
+	client, err := clientset.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return s, client
+}
+
+// get returns a copy of the stored Ingress
+func (s *fakeAPIServer) get(namespace, name string) *networking.Ingress {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ing, ok := s.ingresses[namespace+"/"+name]
+	if !ok {
+		s.t.Fatalf("Ingress %v/%v not found", namespace, name)
+	}
+
+	return ing.DeepCopy()
+}
+
+// bump increments the resourceVersion of the server and assigns it to the Ingress
+func (s *fakeAPIServer) bump(ing *networking.Ingress) {
+	s.resourceVersion++
+	ing.ResourceVersion = strconv.FormatInt(s.resourceVersion, 10)
+}
+
+func (s *fakeAPIServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, ingressesPath), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "ingresses" && r.Method == http.MethodGet:
+		s.list(w)
+	case len(parts) >= 4 && parts[0] == "namespaces" && parts[2] == "ingresses":
+		ing, ok := s.ingresses[parts[1]+"/"+parts[3]]
+		if !ok {
+			writeStatus(w, apierrors.NewNotFound(networking.Resource("ingresses"), parts[3]))
+			return
+		}
+
+		switch {
+		case len(parts) == 4 && r.Method == http.MethodGet:
+			writeIngress(w, ing)
+		case len(parts) == 4 && r.Method == http.MethodPatch:
+			s.patch(w, r, ing)
+		case len(parts) == 5 && parts[4] == "status" && r.Method == http.MethodPut:
+			s.updateStatus(w, r, ing)
+		default:
+			http.Error(w, "unsupported request", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *fakeAPIServer) list(w http.ResponseWriter) {
+	list := &networking.IngressList{
+		TypeMeta: metav1.TypeMeta{Kind: "IngressList", APIVersion: networking.SchemeGroupVersion.String()},
+		ListMeta: metav1.ListMeta{ResourceVersion: strconv.FormatInt(s.resourceVersion, 10)},
+	}
+	for _, ing := range s.ingresses {
+		list.Items = append(list.Items, *ing)
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// patch applies a JSON merge patch to the Ingress
+func (s *fakeAPIServer) patch(w http.ResponseWriter, r *http.Request, ing *networking.Ingress) {
+	if types.PatchType(r.Header.Get("Content-Type")) != types.MergePatchType {
+		http.Error(w, "unsupported patch type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	patch, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+
+	original, err := json.Marshal(ing)
+	if err != nil {
+		writeStatus(w, apierrors.NewInternalError(err))
+		return
+	}
+
+	patched, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		writeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+
+	result := &networking.Ingress{}
+	if err := json.Unmarshal(patched, result); err != nil {
+		writeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+
+	if result.ResourceVersion != ing.ResourceVersion {
+		writeStatus(w, apierrors.NewConflict(networking.Resource("ingresses"), ing.Name, fmt.Errorf("stale resourceVersion")))
+		return
+	}
+
+	// the status is not modified by a patch of the main resource
+	result.Status = ing.Status
+	s.bump(result)
+	s.ingresses[ing.Namespace+"/"+ing.Name] = result
+
+	writeIngress(w, result)
+}
+
+// updateStatus replaces the status of the Ingress, rejecting the update if
+// the resourceVersion is not the stored one
+func (s *fakeAPIServer) updateStatus(w http.ResponseWriter, r *http.Request, ing *networking.Ingress) {
+	update := &networking.Ingress{}
+	if err := json.NewDecoder(r.Body).Decode(update); err != nil {
+		writeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+
+	if s.beforeUpdateStatus != nil {
+		s.beforeUpdateStatus(ing)
+	}
+
+	if update.ResourceVersion != ing.ResourceVersion {
+		writeStatus(w, apierrors.NewConflict(networking.Resource("ingresses"), ing.Name,
+			fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again")))
+		return
+	}
+
+	ing.Status = update.Status
+	s.bump(ing)
+
+	writeIngress(w, ing)
+}
+
+func writeIngress(w http.ResponseWriter, ing *networking.Ingress) {
+	ing = ing.DeepCopy()
+	ing.TypeMeta = metav1.TypeMeta{Kind: "Ingress", APIVersion: networking.SchemeGroupVersion.String()}
+
+	writeJSON(w, http.StatusOK, ing)
+}
+
+func writeStatus(w http.ResponseWriter, err *apierrors.StatusError) {
+	status := err.ErrStatus
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+
+	writeJSON(w, int(status.Code), &status)
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(obj)
+}
+
+func buildIntegrationIngress(status ...apiv1.LoadBalancerIngress) *networking.Ingress {
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo_ingress",
+			Namespace:  apiv1.NamespaceDefault,
+			Generation: 1,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{Ingress: status},
+		},
+	}
+}
+
+func buildIntegrationStatusSync(client clientset.Interface) statusSync {
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+
+	return fk
+}
+
+func TestIntegrationSyncWritesStatus(t *testing.T) {
+	server, client := newFakeAPIServer(t, buildIntegrationIngress())
+	fk := buildIntegrationStatusSync(client)
+
+	before := server.get(apiv1.NamespaceDefault, "foo_ingress")
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing := server.get(apiv1.NamespaceDefault, "foo_ingress")
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	if !isGenerationObserved(ing) {
+		t.Errorf("expected the generation of the Ingress to be observed but returned %v", ing.Annotations)
+	}
+
+	if ing.ResourceVersion == before.ResourceVersion {
+		t.Errorf("expected the resourceVersion %v to change", before.ResourceVersion)
+	}
+
+	// the Ingress up to date is not written again
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if current := server.get(apiv1.NamespaceDefault, "foo_ingress"); current.ResourceVersion != ing.ResourceVersion {
+		t.Errorf("expected the resourceVersion %v to be unchanged but returned %v", ing.ResourceVersion, current.ResourceVersion)
+	}
+}
+
+func TestIntegrationSyncConflict(t *testing.T) {
+	server, client := newFakeAPIServer(t, buildIntegrationIngress(apiv1.LoadBalancerIngress{IP: "10.0.0.9"}))
+	fk := buildIntegrationStatusSync(client)
+
+	// another controller writes the status between the read and the update
+	modified := false
+	server.beforeUpdateStatus = func(ing *networking.Ingress) {
+		if modified {
+			return
+		}
+
+		modified = true
+		ing.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: "10.0.0.8"}}
+		server.bump(ing)
+	}
+
+	err := fk.sync("just-test")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !isRetryableError(err) {
+		t.Errorf("expected a retryable error but returned %v", err)
+	}
+
+	ing := server.get(apiv1.NamespaceDefault, "foo_ingress")
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.8"}}
+	if !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("expected the concurrent status to be kept but returned %v", ing.Status.LoadBalancer.Ingress)
+	}
+
+	// the retry reads the latest version
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing = server.get(apiv1.NamespaceDefault, "foo_ingress")
+	expected = []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
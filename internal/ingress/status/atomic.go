@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+)
+
+// atomicSyncKey is the context key marking the syncs started by syncAtomic
+type atomicSyncKey struct{}
+
+// syncAtomic updates the status of the claimed Ingresses together, for
+// cutovers like moving a VIP. The status of all the Ingresses is computed
+// before the first write, and nothing is written if it fails for any of
+// them, so the Ingresses switch addresses in the same sync.
+func (s *statusSync) syncAtomic(ctx context.Context) (syncResult, error) {
+	return s.reconcile(context.WithValue(ctx, atomicSyncKey{}, true))
+}
+
+// isAtomicSync checks if the sync was started by syncAtomic
+func isAtomicSync(ctx context.Context) bool {
+	atomic, _ := ctx.Value(atomicSyncKey{}).(bool)
+	return atomic
+}
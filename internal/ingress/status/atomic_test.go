@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+func buildAtomicIngress(name string, annotations map[string]string) *networking.Ingress {
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   apiv1.NamespaceDefault,
+			Annotations: annotations,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+}
+
+func TestSyncAtomic(t *testing.T) {
+	names := []string{"foo_1", "foo_2", "foo_3"}
+
+	client := testclient.NewSimpleClientset()
+	for _, name := range names {
+		if err := client.Tracker().Add(buildAtomicIngress(name, nil)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.9"
+	fk.IngressLister = &clientIngressLister{client}
+
+	r, err := fk.syncAtomic(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != len(names) {
+		t.Errorf("expected %v updated Ingresses but returned %+v", len(names), r)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.9"}}
+	for _, name := range names {
+		if status := getIngressStatus(t, client, name); !reflect.DeepEqual(status, expected) {
+			t.Errorf("%v: returned %v but expected %v", name, status, expected)
+		}
+	}
+}
+
+func TestSyncAtomicAborted(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		buildAtomicIngress("foo_1", nil),
+		// the primary Ingress of the alias does not exist
+		buildAtomicIngress("foo_alias", map[string]string{
+			parser.GetAnnotationWithPrefix(statusAliasAnnotation): "default/missing",
+		}),
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.9"
+	fk.IngressLister = &clientIngressLister{client}
+
+	r, err := fk.syncAtomic(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 0 || r.failed != 1 {
+		t.Errorf("expected no updated Ingress and one failed but returned %+v", r)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if status := getIngressStatus(t, client, "foo_1"); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+
+	// a regular sync updates the rest of the Ingresses
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []apiv1.LoadBalancerIngress{{IP: "10.0.0.9"}}
+	if status := getIngressStatus(t, client, "foo_1"); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/klog/v2"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/k8s"
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// ingressKey is the namespace/name of an Ingress enqueued to update
+// only its status
+type ingressKey string
+
+// statusCache keeps the addresses published in the last sync
+type statusCache struct {
+	lock sync.RWMutex
+
+	addresses []apiv1.LoadBalancerIngress
+}
+
+// set saves a copy of the published addresses
+func (c *statusCache) set(addrs []apiv1.LoadBalancerIngress) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.addresses = append([]apiv1.LoadBalancerIngress{}, addrs...)
+}
+
+// get returns a copy of the published addresses, or nil
+// if the status was not synced yet
+func (c *statusCache) get() []apiv1.LoadBalancerIngress {
+	if c == nil {
+		return nil
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.addresses == nil {
+		return nil
+	}
+
+	return append([]apiv1.LoadBalancerIngress{}, c.addresses...)
+}
+
+// EnqueueIngress enqueues the update of the status of a new Ingress, so
+// it does not have to wait for the next periodic sync. Ignored if this
+// instance is not the leader.
+func (s *statusSync) EnqueueIngress(ing *networking.Ingress) {
+	if atomic.LoadInt32(&s.leading) == 0 {
+		return
+	}
+
+	// the sync of a single Ingress must not skip the pending full syncs
+	s.syncQueue.EnqueueIsolatedTask(ingressKey(k8s.MetaNamespaceKey(ing)))
+}
+
+// syncIngress updates the status of a single Ingress with the addresses
+// published in the last sync. If there was no sync yet, all the Ingresses
+// are reconciled.
+func (s *statusSync) syncIngress(ctx context.Context, key ingressKey) (syncResult, error) {
+	status := s.lastStatus.get()
+	if status == nil {
+		return s.reconcile(ctx)
+	}
+
+	if s.isSyncSuspended() {
+		return syncResult{}, nil
+	}
+
+	for _, ing := range s.listIngresses() {
+		if fmt.Sprintf("%v/%v", ing.Namespace, ing.Name) != string(key) {
+			continue
+		}
+
+		return s.updateIngresses(ctx, []*ingress.Ingress{ing}, status), nil
+	}
+
+	klog.V(3).InfoS("skipping update of Ingress (not found)", "ingress", key)
+	return syncResult{}, nil
+}
+
+// ingressKeyFromElement returns the Ingress of a queue element
+// enqueued by EnqueueIngress
+func ingressKeyFromElement(key interface{}) (ingressKey, bool) {
+	elem, ok := key.(task.Element)
+	if !ok {
+		return "", false
+	}
+
+	ik, ok := elem.Key.(ingressKey)
+	return ik, ok
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+func buildNewIngress(name string) *networking.Ingress {
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+}
+
+func TestSyncNewIngress(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Create(context.TODO(), buildNewIngress("foo_ingress_new"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the addresses of the last sync are used, even if the source changed
+	fk.PublishStatusAddress = "10.0.0.2"
+
+	err = fk.sync(task.Element{Key: ingressKey("default/foo_ingress_new")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), ing.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
+
+func TestSyncNewIngressWithoutPreviousSync(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+
+	r, err := fk.syncIngress(context.TODO(), ingressKey("default/foo_ingress_1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// all the Ingresses in the API server are reconciled
+	if r.updated != 3 {
+		t.Errorf("expected three updated Ingresses but returned %+v", r)
+	}
+}
+
+func TestEnqueueIngress(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.syncQueue = task.NewCustomTaskQueue(fk.sync, fk.keyfunc)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go fk.syncQueue.Run(time.Second, stopCh)
+	defer fk.syncQueue.Shutdown()
+
+	fk.lastStatus.set([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Create(context.TODO(), buildNewIngress("foo_ingress_new"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hasStatus := func() (bool, error) {
+		cur, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), ing.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		return len(cur.Status.LoadBalancer.Ingress) > 0, nil
+	}
+
+	// ignored when the instance is not the leader
+	fk.EnqueueIngress(ing)
+	time.Sleep(100 * time.Millisecond)
+	if ok, _ := hasStatus(); ok {
+		t.Fatalf("unexpected status update in an instance that is not the leader")
+	}
+
+	fk.setLeading(true)
+	fk.EnqueueIngress(ing)
+
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, hasStatus)
+	if err != nil {
+		t.Errorf("expected the status of the new Ingress to be updated: %v", err)
+	}
+}
+
+func TestEnqueueIngressAfterFailedSync(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.IngressLister = &clientIngressLister{client}
+	fk.syncQueue = task.NewCustomTaskQueue(fk.sync, fk.keyfunc)
+	fk.setLeading(true)
+	fk.lastStatus.set([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Create(context.TODO(), buildNewIngress("foo_ingress_new"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the first full sync fails with a transient error reading the publish
+	// service, and the new Ingress is enqueued before the retry
+	var gets int32
+	client.PrependReactor("get", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if atomic.AddInt32(&gets, 1) > 1 {
+			return false, nil, nil
+		}
+
+		fk.EnqueueIngress(ing)
+		return true, nil, apierrors.NewServerTimeout(apiv1.Resource("services"), "get", 1)
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go fk.syncQueue.Run(time.Second, stopCh)
+	defer fk.syncQueue.Shutdown()
+
+	fk.syncQueue.EnqueueTask(task.GetDummyObject("sync status"))
+
+	// the sync of the new Ingress does not skip the retry of the full sync
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return atomic.LoadInt32(&gets) > 1, nil
+	})
+	if err != nil {
+		t.Errorf("expected the failed sync to be retried: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// defaultAddressCommandTimeout is the max duration of the AddressCommand
+// when AddressCommandTimeout is not set
+const defaultAddressCommandTimeout = 10 * time.Second
+
+// commandAddresses runs the AddressCommand and returns the addresses
+// printed in its standard output. Empty lines are ignored. A failed
+// command or an invalid address returns an error, retried in the next sync.
+func (s *statusSync) commandAddresses(ctx context.Context) ([]string, error) {
+	timeout := s.AddressCommandTimeout
+	if timeout <= 0 {
+		timeout = defaultAddressCommandTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.AddressCommand[0], s.AddressCommand[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("address command %v timed out after %v", s.AddressCommand[0], timeout)
+		}
+
+		return nil, fmt.Errorf("running address command %v: %v: %v", s.AddressCommand[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	addrs := []string{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		addr := strings.TrimSpace(scanner.Text())
+		if addr == "" {
+			continue
+		}
+
+		if !isValidAddress(addr) {
+			return nil, fmt.Errorf("invalid address %q returned by the address command %v", addr, s.AddressCommand[0])
+		}
+
+		if !stringInSlice(addr, addrs) {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading the output of the address command %v: %v", s.AddressCommand[0], err)
+	}
+
+	return addrs, nil
+}
+
+// isValidAddress returns true if addr is an IP address or a DNS hostname
+func isValidAddress(addr string) bool {
+	if net.ParseIP(addr) != nil {
+		return true
+	}
+
+	return len(validation.IsDNS1123Subdomain(addr)) == 0
+}
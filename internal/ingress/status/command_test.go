@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddressCommand(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.AddressCommand = []string{"sh", "-c", `printf '10.0.0.5\n\n lb.example.com \n10.0.0.5\n'`}
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.5"}, {Hostname: "lb.example.com"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
+
+func TestAddressCommandErrors(t *testing.T) {
+	testCases := map[string][]string{
+		"exit code":       {"sh", "-c", "echo 10.0.0.5; exit 1"},
+		"invalid address": {"sh", "-c", "echo 'not an address'"},
+		"timeout":         {"sleep", "5"},
+		"missing command": {"/non/existent/command"},
+	}
+
+	for name, command := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.PublishService = ""
+			fk.AddressCommand = command
+			fk.AddressCommandTimeout = 100 * time.Millisecond
+
+			addrs, err := fk.runningAddresses()
+			if err == nil {
+				t.Fatalf("expected an error but returned %v", addrs)
+			}
+
+			if !isRetryableError(err) {
+				t.Errorf("expected a retryable error but returned %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+)
+
+// defaultStatusConflictWindow is the StatusConflictWindow used if not set
+const defaultStatusConflictWindow = 10 * time.Minute
+
+// statusRevert is a change of the status written by the controller made by
+// another writer
+type statusRevert struct {
+	at    time.Time
+	value string
+}
+
+// statusConflictDetector detects the Ingresses whose status is changed by
+// another writer after each update, like a second controller claiming them
+type statusConflictDetector struct {
+	lock sync.Mutex
+
+	clock clock.Clock
+
+	// written contains the last status written in each Ingress
+	written map[string]string
+	// reverts contains the changes of the written status within the window
+	reverts map[string][]statusRevert
+	// reported contains the Ingresses with a conflict already reported
+	reported map[string]bool
+}
+
+func newStatusConflictDetector(c clock.Clock) *statusConflictDetector {
+	return &statusConflictDetector{
+		clock:    c,
+		written:  map[string]string{},
+		reverts:  map[string][]statusRevert{},
+		reported: map[string]bool{},
+	}
+}
+
+// observe records a write of the status of the Ingress key, replacing the
+// previous value. Returns the competing values once the written status was
+// changed by another writer threshold times within the window.
+func (d *statusConflictDetector) observe(key string, previous, written []apiv1.LoadBalancerIngress,
+	threshold int, window time.Duration) ([]string, bool) {
+	if d == nil {
+		return nil, false
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.clock.Now()
+
+	reverts := make([]statusRevert, 0, len(d.reverts[key])+1)
+	for _, revert := range d.reverts[key] {
+		if now.Sub(revert.at) < window {
+			reverts = append(reverts, revert)
+		}
+	}
+
+	if len(reverts) == 0 {
+		delete(d.reported, key)
+	}
+
+	value := conflictValue(previous)
+	if last, ok := d.written[key]; ok && last != value {
+		reverts = append(reverts, statusRevert{at: now, value: value})
+	}
+
+	d.written[key] = conflictValue(written)
+	d.reverts[key] = reverts
+
+	if len(reverts) < threshold || d.reported[key] {
+		return nil, false
+	}
+	d.reported[key] = true
+
+	values := []string{}
+	for _, revert := range reverts {
+		if !stringInSlice(revert.value, values) {
+			values = append(values, revert.value)
+		}
+	}
+	sort.Strings(values)
+
+	return values, true
+}
+
+// conflictValue returns the addresses as a sorted comma separated list, to
+// compare statuses regardless of the order of the addresses
+func conflictValue(addrs []apiv1.LoadBalancerIngress) string {
+	values := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		values = append(values, loadBalancerAddress(addr))
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, ",")
+}
+
+// retain removes the Ingresses not included in keys
+func (d *statusConflictDetector) retain(keys map[string]bool) {
+	if d == nil {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for key := range d.written {
+		if !keys[key] {
+			delete(d.written, key)
+			delete(d.reverts, key)
+			delete(d.reported, key)
+		}
+	}
+}
+
+// checkStatusConflict reports the Ingresses whose status is changed by
+// another writer StatusConflictThreshold times within the
+// StatusConflictWindow
+func (s *statusSync) checkStatusConflict(ing *networking.Ingress, previous, written []apiv1.LoadBalancerIngress) {
+	if s.StatusConflictThreshold <= 0 {
+		return
+	}
+
+	window := s.StatusConflictWindow
+	if window <= 0 {
+		window = defaultStatusConflictWindow
+	}
+
+	key := ing.Namespace + "/" + ing.Name
+	values, conflict := s.conflicts.observe(key, previous, written, s.StatusConflictThreshold, window)
+	if !conflict {
+		return
+	}
+
+	klog.Warningf("status of Ingress %v changed by another writer %v times in %v (written %v, competing values %v), another controller may be updating it",
+		key, s.StatusConflictThreshold, window, conflictValue(written), values)
+
+	if s.Recorder != nil {
+		s.Recorder.Eventf(ing, apiv1.EventTypeWarning, "StatusConflict",
+			"Ingress status changed by another writer %v times in %v (competing values %v)", s.StatusConflictThreshold, window, values)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+func TestStatusConflict(t *testing.T) {
+	var buf logBuffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&buf)
+	defer func() {
+		klog.SetOutput(os.Stderr)
+		klog.LogToStderr(true)
+	}()
+
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	recorder := record.NewFakeRecorder(10)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.Recorder = recorder
+	fk.StatusConflictThreshold = 2
+	fk.StatusConflictWindow = time.Minute
+	fk.conflicts = newStatusConflictDetector(fakeClock)
+
+	// another writer reverts the status after each sync
+	for i := 0; i < 4; i++ {
+		if _, err := fk.reconcile(context.TODO()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ing.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: fmt.Sprintf("192.168.0.%v", i%2+1)}}
+		if _, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).UpdateStatus(context.TODO(), ing, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		fakeClock.Step(10 * time.Second)
+	}
+	klog.Flush()
+
+	expected := "status of Ingress default/foo_ingress changed by another writer 2 times in 1m0s (written 10.0.0.1, competing values [192.168.0.1 192.168.0.2])"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the log to contain %v but returned %v", expected, buf.String())
+	}
+
+	// the conflict is reported once
+	events := 0
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, "Warning StatusConflict") {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("expected one conflict event but returned %v", events)
+	}
+}
+
+func TestStatusConflictDetector(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	d := newStatusConflictDetector(fakeClock)
+
+	written := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	external := []apiv1.LoadBalancerIngress{{IP: "192.168.0.1"}}
+
+	// the first write and the writes replacing the last written status
+	// are not conflicts
+	for i := 0; i < 3; i++ {
+		if values, ok := d.observe("default/foo", written, written, 1, time.Minute); ok {
+			t.Fatalf("unexpected conflict %v", values)
+		}
+	}
+
+	if _, ok := d.observe("default/foo", external, written, 1, time.Minute); !ok {
+		t.Errorf("expected a conflict")
+	}
+
+	// the conflict is reported again after the window without changes
+	fakeClock.Step(2 * time.Minute)
+	if _, ok := d.observe("default/foo", external, written, 1, time.Minute); !ok {
+		t.Errorf("expected a new conflict after the window")
+	}
+
+	d.retain(map[string]bool{})
+	if len(d.written) != 0 || len(d.reverts) != 0 || len(d.reported) != 0 {
+		t.Errorf("expected the removed Ingresses to be cleaned up")
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// addressDebouncer coalesces the syncs triggered by address changes, like
+// the churn of nodes during an autoscaling. The first change is synced
+// immediately, and the changes received before the end of the window are
+// synced once when the window ends.
+type addressDebouncer struct {
+	lock sync.Mutex
+
+	clock  clock.Clock
+	window time.Duration
+
+	// enqueue triggers a sync of the status
+	enqueue func(reason string)
+
+	// last is the time of the last enqueued sync
+	last time.Time
+	// pending is true when a sync is scheduled at the end of the window
+	pending bool
+}
+
+func newAddressDebouncer(c clock.Clock, window time.Duration, enqueue func(reason string)) *addressDebouncer {
+	return &addressDebouncer{
+		clock:   c,
+		window:  window,
+		enqueue: enqueue,
+	}
+}
+
+// trigger enqueues a sync, or schedules it at the end of the window if a
+// sync was enqueued recently
+func (d *addressDebouncer) trigger(reason string) {
+	if d.window <= 0 {
+		d.enqueue(reason)
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.pending {
+		klog.V(3).InfoS("coalescing address change in the pending status sync", "reason", reason)
+		return
+	}
+
+	now := d.clock.Now()
+	if d.last.IsZero() || now.Sub(d.last) >= d.window {
+		d.last = now
+		d.enqueue(reason)
+		return
+	}
+
+	d.pending = true
+	timer := d.clock.NewTimer(d.window - now.Sub(d.last))
+	go func() {
+		<-timer.C()
+
+		d.lock.Lock()
+		d.pending = false
+		d.last = d.clock.Now()
+		d.lock.Unlock()
+
+		d.enqueue(reason)
+	}()
+}
+
+// enqueueAddressChange enqueues a sync of the status after a change of the
+// addresses, coalesced with the other changes within the DebounceWindow
+func (s *statusSync) enqueueAddressChange(reason string) {
+	if s.debouncer == nil {
+		s.syncQueue.EnqueueTask(task.GetDummyObject(reason))
+		return
+	}
+
+	s.debouncer.trigger(reason)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// syncCounter counts the syncs enqueued by an addressDebouncer
+type syncCounter struct {
+	lock    sync.Mutex
+	reasons []string
+}
+
+func (c *syncCounter) enqueue(reason string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.reasons = append(c.reasons, reason)
+}
+
+func (c *syncCounter) count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.reasons)
+}
+
+func TestDebounceNodeChurn(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	counter := &syncCounter{}
+
+	fk := buildStatusSync()
+	fk.debouncer = newAddressDebouncer(fakeClock, 10*time.Second, counter.enqueue)
+
+	node := func(name string) *apiv1.Node {
+		return &apiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	// the first change is synced immediately
+	fk.nodeDeleted(node("foo_node_1"))
+	if n := counter.count(); n != 1 {
+		t.Fatalf("expected one sync but returned %v", n)
+	}
+
+	// the following changes are coalesced
+	for _, name := range []string{"foo_node_2", "foo_node_3", "foo_node_4"} {
+		fakeClock.Step(time.Second)
+		fk.nodeDeleted(node(name))
+	}
+	if n := counter.count(); n != 1 {
+		t.Fatalf("expected the changes to be coalesced but returned %v syncs", n)
+	}
+
+	fakeClock.Step(7 * time.Second)
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return counter.count() == 2, nil
+	})
+	if err != nil {
+		t.Fatalf("expected a single sync of the coalesced changes but returned %v syncs", counter.count())
+	}
+
+	// a change after the window is synced immediately
+	fakeClock.Step(10 * time.Second)
+	fk.nodeDeleted(node("foo_node_5"))
+	if n := counter.count(); n != 3 {
+		t.Errorf("expected three syncs but returned %v", n)
+	}
+}
+
+func TestDebounceDisabled(t *testing.T) {
+	counter := &syncCounter{}
+	debouncer := newAddressDebouncer(clock.NewFakeClock(time.Now()), 0, counter.enqueue)
+
+	for i := 0; i < 3; i++ {
+		debouncer.trigger("node removed")
+	}
+
+	if n := counter.count(); n != 3 {
+		t.Errorf("expected three syncs but returned %v", n)
+	}
+}
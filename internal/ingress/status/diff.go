@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// IngressStatusDiff contains the current and the desired status of an Ingress
+type IngressStatusDiff struct {
+	Namespace string                      `json:"namespace"`
+	Name      string                      `json:"name"`
+	Current   []apiv1.LoadBalancerIngress `json:"current"`
+	Desired   []apiv1.LoadBalancerIngress `json:"desired"`
+}
+
+// SyncDiff returns the claimed Ingresses with a status different from the
+// addresses to publish, sorted by namespace and name. The state of the
+// syncer is not modified: the status is not updated, the addresses are not
+// probed and the address drainer, the stabilizer and the grace period of
+// an empty status are not applied, so the desired status can differ from
+// the one written by the next sync.
+func (s *statusSync) SyncDiff(ctx context.Context) ([]IngressStatusDiff, error) {
+	addrs, err := s.previewAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := s.rewriteHostnames(sliceToStatus(addrs))
+	s.sortStatus(status)
+	equal := s.statusEqualFunc()
+	typeStatus := addressTypeStatus{}
+	index := newIngressIndex(s.listIngresses)
+	vips := s.newVIPPool(ctx)
+
+	diffs := []IngressStatusDiff{}
+	for _, ing := range s.listIngresses() {
+		if !s.isClaimed(ing) {
+			continue
+		}
+
+		ready, found := s.ingressReadiness(&ing.Ingress)
+		if !found {
+			continue
+		}
+
+		source, err := s.statusSource(ing, index)
+		if err != nil {
+			klog.Warningf("skipping Ingress %v/%v: %v", ing.Namespace, ing.Name, err)
+			continue
+		}
+
+		point, err := s.ingressAddresses(ctx, &source.Ingress, status, typeStatus, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if vip, ok := s.ingressVIP(&source.Ingress, vips); ok {
+			point = vip
+		}
+
+		addrs := s.limitStatus(s.ingressStatus(&source.Ingress, point))
+		if !ready {
+			addrs = []apiv1.LoadBalancerIngress{}
+		}
+
+		desired := s.desiredStatus(&ing.Ingress, addrs)
+
+		curIPs := s.currentAddresses(&ing.Ingress)
+		s.sortStatus(curIPs)
+		if equal(curIPs, desired) {
+			continue
+		}
+
+		diffs = append(diffs, IngressStatusDiff{
+			Namespace: ing.Namespace,
+			Name:      ing.Name,
+			Current:   curIPs,
+			Desired:   append([]apiv1.LoadBalancerIngress{}, desired...),
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Namespace != diffs[j].Namespace {
+			return diffs[i].Namespace < diffs[j].Namespace
+		}
+
+		return diffs[i].Name < diffs[j].Name
+	})
+
+	return diffs, nil
+}
+
+// previewAddresses returns the addresses to publish like publishedAddresses,
+// without probing them nor updating the running address state and cache
+func (s *statusSync) previewAddresses(ctx context.Context) ([]string, error) {
+	addrs, err := s.runningAddresses()
+	if err != nil {
+		cached, ok := s.cachedRunningAddresses(err)
+		if !ok {
+			return nil, err
+		}
+
+		addrs = cached
+	}
+
+	addrs = s.resolvedAddresses(ctx, addrs)
+	addrs = s.allowedAddresses(addrs)
+	addrs = s.withStaticAddresses(addrs)
+
+	return addrs, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncDiff(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	// foo_ingress_1 already contains the running address
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ing.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if _, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).UpdateStatus(context.TODO(), ing, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+
+	diffs, err := fk.SyncDiff(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	desired := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	expected := []IngressStatusDiff{
+		{
+			Namespace: apiv1.NamespaceDefault,
+			Name:      "foo_ingress_2",
+			Current:   []apiv1.LoadBalancerIngress{},
+			Desired:   desired,
+		},
+		{
+			Namespace: apiv1.NamespaceDefault,
+			Name:      "foo_ingress_different_class",
+			Current:   []apiv1.LoadBalancerIngress{{IP: "0.0.0.0", Hostname: "foo.bar.com"}},
+			Desired:   desired,
+		},
+	}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("returned %+v but expected %+v", diffs, expected)
+	}
+
+	// the status is not updated
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ing.Status.LoadBalancer.Ingress) != 0 {
+		t.Errorf("unexpected update of the status of foo_ingress_2: %v", ing.Status.LoadBalancer.Ingress)
+	}
+}
+
+func TestSyncDiffWithoutSideEffects(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.AddressHealthCheck = &AddressHealthCheck{Port: 80}
+	fk.dial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		t.Errorf("unexpected health check of %v", address)
+		return nil, fmt.Errorf("unexpected health check")
+	}
+
+	state := fk.AddressState()
+	if _, err := fk.SyncDiff(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fk.AddressState() != state {
+		t.Errorf("expected the address state %v but returned %v", state, fk.AddressState())
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+)
+
+// addressDrainer keeps the addresses removed from the status published
+// during a drain period, so the traffic can be moved to the new addresses
+type addressDrainer struct {
+	lock sync.Mutex
+
+	clock clock.Clock
+
+	// published contains the addresses returned in the last call to drain
+	published []string
+	// removedAt contains the time each draining address was removed
+	removedAt map[string]time.Time
+}
+
+func newAddressDrainer(c clock.Clock) *addressDrainer {
+	return &addressDrainer{
+		clock:     c,
+		removedAt: map[string]time.Time{},
+	}
+}
+
+// drain returns the running addresses plus the previously published ones
+// removed less than period ago. New addresses are returned immediately.
+func (d *addressDrainer) drain(addrs []string, period time.Duration) []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.clock.Now()
+
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		delete(d.removedAt, addr)
+		result = append(result, addr)
+	}
+
+	for _, addr := range d.published {
+		if stringInSlice(addr, result) {
+			continue
+		}
+
+		removedAt, ok := d.removedAt[addr]
+		if !ok {
+			removedAt = now
+			d.removedAt[addr] = now
+		}
+
+		if now.Sub(removedAt) >= period {
+			klog.InfoS("address drain period finished, removing it from the status", "address", addr)
+			delete(d.removedAt, addr)
+			continue
+		}
+
+		klog.V(2).InfoS("keeping removed address in the status during the drain period", "address", addr, "removedAt", removedAt)
+		result = append(result, addr)
+	}
+
+	d.published = result
+
+	return result
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestAddressDrain(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.AddressDrainPeriod = time.Minute
+	fk.drainer = newAddressDrainer(fakeClock)
+
+	checkStatus := func(expected []apiv1.LoadBalancerIngress) {
+		if _, err := fk.reconcile(context.TODO()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+			t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+		}
+	}
+
+	fk.PublishStatusAddress = "10.0.0.1"
+	checkStatus([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+
+	// the new address is published immediately and the old one is drained
+	fk.PublishStatusAddress = "10.0.0.2"
+	checkStatus([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}})
+
+	fakeClock.Step(30 * time.Second)
+	checkStatus([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}})
+
+	fakeClock.Step(30 * time.Second)
+	checkStatus([]apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}})
+}
+
+func TestAddressDrainReturningAddress(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	d := newAddressDrainer(fakeClock)
+
+	d.drain([]string{"10.0.0.1"}, time.Minute)
+	d.drain([]string{"10.0.0.2"}, time.Minute)
+
+	fakeClock.Step(45 * time.Second)
+	// the address is running again so the removal time is reset
+	d.drain([]string{"10.0.0.1", "10.0.0.2"}, time.Minute)
+	d.drain([]string{"10.0.0.2"}, time.Minute)
+
+	fakeClock.Step(45 * time.Second)
+	r := d.drain([]string{"10.0.0.2"}, time.Minute)
+
+	expected := []string{"10.0.0.2", "10.0.0.1"}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("returned %v but expected %v", r, expected)
+	}
+}
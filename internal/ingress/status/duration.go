@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// LastSyncDuration returns the duration of the last sync
+func (s *statusSync) LastSyncDuration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.lastSyncDuration))
+}
+
+// observeSyncDuration records the duration of the sync started at start
+func (s *statusSync) observeSyncDuration(start time.Time) {
+	d := s.now().Sub(start)
+	atomic.StoreInt64(&s.lastSyncDuration, int64(d))
+	s.metrics.setLastSyncDuration(d)
+}
+
+// now returns the current time of the clock of the syncer
+func (s *statusSync) now() time.Time {
+	if s.clock == nil {
+		return clock.RealClock{}.Now()
+	}
+
+	return s.clock.Now()
+}
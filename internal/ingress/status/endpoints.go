@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// statusAddressFromEndpoints returns the IP addresses of the ready
+// endpoints of the service. Returns an error if no endpoint is ready,
+// to keep the current status instead of removing it.
+func statusAddressFromEndpoints(service string, kubeClient clientset.Interface) ([]string, error) {
+	ns, name, _ := k8s.ParseNameNS(service)
+	eps, err := kubeClient.CoreV1().Endpoints(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := readyEndpointAddresses(eps)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("publish service %v does not have ready endpoints", service)
+	}
+
+	return addrs, nil
+}
+
+// readyEndpointAddresses returns the IP addresses of the ready endpoints
+// in the subsets. The addresses in NotReadyAddresses are skipped.
+func readyEndpointAddresses(eps *apiv1.Endpoints) []string {
+	addrs := []string{}
+	for _, subset := range eps.Subsets {
+		for _, addr := range subset.Addresses {
+			if !stringInSlice(addr.IP, addrs) {
+				addrs = append(addrs, addr.IP)
+			}
+		}
+	}
+
+	return addrs
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func buildPublishEndpoints(subsets ...apiv1.EndpointSubset) *apiv1.Endpoints {
+	return &apiv1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Subsets: subsets,
+	}
+}
+
+func TestPublishReadyEndpoints(t *testing.T) {
+	svc := &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: apiv1.NamespaceDefault,
+			Labels:    map[string]string{"app": "ingress-nginx"},
+		},
+		Spec: apiv1.ServiceSpec{
+			Type:      apiv1.ServiceTypeClusterIP,
+			ClusterIP: "10.96.0.10",
+		},
+	}
+
+	eps := buildPublishEndpoints(
+		apiv1.EndpointSubset{
+			Addresses:         []apiv1.EndpointAddress{{IP: "10.0.1.1"}},
+			NotReadyAddresses: []apiv1.EndpointAddress{{IP: "10.0.1.2"}},
+		},
+		apiv1.EndpointSubset{
+			NotReadyAddresses: []apiv1.EndpointAddress{{IP: "10.0.1.3"}},
+		},
+		apiv1.EndpointSubset{
+			Addresses: []apiv1.EndpointAddress{{IP: "10.0.1.4"}, {IP: "10.0.1.1"}},
+		},
+	)
+
+	fk := buildStatusSync()
+	fk.Client = testclient.NewSimpleClientset(svc, eps)
+	fk.PublishReadyEndpoints = true
+
+	expected := []string{"10.0.1.1", "10.0.1.4"}
+
+	addrs, err := fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("returned %v but expected %v", addrs, expected)
+	}
+
+	fk.PublishService = ""
+	fk.PublishServiceSelector = labels.SelectorFromSet(labels.Set{"app": "ingress-nginx"})
+
+	addrs, err = fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("returned %v but expected %v", addrs, expected)
+	}
+}
+
+func TestPublishReadyEndpointsNotReady(t *testing.T) {
+	eps := buildPublishEndpoints(apiv1.EndpointSubset{
+		NotReadyAddresses: []apiv1.EndpointAddress{{IP: "10.0.1.2"}},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = testclient.NewSimpleClientset(eps)
+	fk.PublishReadyEndpoints = true
+
+	addrs, err := fk.runningAddresses()
+	if err == nil {
+		t.Errorf("expected an error without ready endpoints but returned %v", addrs)
+	}
+}
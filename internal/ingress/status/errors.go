@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// UpdateError contains the last error updating the status of an Ingress
+type UpdateError struct {
+	// Message is the description of the error
+	Message string
+	// Timestamp is the time of the failed update
+	Timestamp time.Time
+}
+
+// updateErrors keeps the last error updating the status of each Ingress
+type updateErrors struct {
+	lock sync.RWMutex
+
+	clock clock.Clock
+
+	errors map[string]UpdateError
+}
+
+func newUpdateErrors(c clock.Clock) *updateErrors {
+	return &updateErrors{
+		clock:  c,
+		errors: map[string]UpdateError{},
+	}
+}
+
+// set records err as the last error of the Ingress key
+func (u *updateErrors) set(key string, err error) {
+	if u == nil {
+		return
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.errors[key] = UpdateError{
+		Message:   err.Error(),
+		Timestamp: u.clock.Now(),
+	}
+}
+
+// clear removes the last error of the Ingress key
+func (u *updateErrors) clear(key string) {
+	if u == nil {
+		return
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	delete(u.errors, key)
+}
+
+// retain removes the errors of the Ingresses not present in keys
+func (u *updateErrors) retain(keys map[string]bool) {
+	if u == nil {
+		return
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	for key := range u.errors {
+		if !keys[key] {
+			delete(u.errors, key)
+		}
+	}
+}
+
+// list returns a copy of the last error of each Ingress
+func (u *updateErrors) list() map[string]UpdateError {
+	result := map[string]UpdateError{}
+	if u == nil {
+		return result
+	}
+
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+
+	for key, err := range u.errors {
+		result[key] = err
+	}
+
+	return result
+}
+
+// isRetryableError returns false for errors that will not be solved
+// retrying the same request, like a missing object or missing permissions
+func isRetryableError(err error) bool {
+	switch {
+	case apierrors.IsNotFound(err),
+		apierrors.IsForbidden(err),
+		apierrors.IsUnauthorized(err),
+		apierrors.IsInvalid(err),
+		apierrors.IsBadRequest(err),
+		apierrors.IsMethodNotSupported(err):
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/k8s"
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// failingStatusWriter returns an error writing the status of the Ingresses in fail
+type failingStatusWriter struct {
+	fail map[string]bool
+	// err is the error returned. Defaults to a generic error
+	err error
+}
+
+func (w *failingStatusWriter) Write(obj interface{}, addresses []apiv1.LoadBalancerIngress) error {
+	ing := obj.(*ingress.Ingress)
+	key := ing.Namespace + "/" + ing.Name
+	if w.fail[key] {
+		if w.err != nil {
+			return w.err
+		}
+
+		return fmt.Errorf("unexpected error updating %v", key)
+	}
+
+	return nil
+}
+
+func TestUpdateErrors(t *testing.T) {
+	now := time.Now()
+
+	writer := &failingStatusWriter{
+		fail: map[string]bool{"default/foo_ingress_1": true},
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.StatusWriter = writer
+	fk.updateErrors = newUpdateErrors(clock.NewFakeClock(now))
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.failed != 1 {
+		t.Errorf("returned %v failed updates but expected %v", r.failed, 1)
+	}
+
+	errs := fk.UpdateErrors()
+	if len(errs) != 1 {
+		t.Fatalf("returned %v errors but expected %v", len(errs), 1)
+	}
+
+	ue, ok := errs["default/foo_ingress_1"]
+	if !ok {
+		t.Fatalf("expected an error for default/foo_ingress_1")
+	}
+	if ue.Message != "unexpected error updating default/foo_ingress_1" {
+		t.Errorf("returned %v but expected %v", ue.Message, "unexpected error updating default/foo_ingress_1")
+	}
+	if !ue.Timestamp.Equal(now) {
+		t.Errorf("returned %v but expected %v", ue.Timestamp, now)
+	}
+
+	// the error is removed after a successful update
+	writer.fail = map[string]bool{}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errs = fk.UpdateErrors()
+	if len(errs) != 0 {
+		t.Errorf("returned %v but expected no errors", errs)
+	}
+}
+
+func TestSyncRequeue(t *testing.T) {
+	resource := schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}
+
+	testCases := map[string]struct {
+		err     error
+		requeue bool
+	}{
+		"not found": {
+			apierrors.NewNotFound(resource, "foo_ingress_1"),
+			false,
+		},
+		"forbidden": {
+			apierrors.NewForbidden(resource, "foo_ingress_1", fmt.Errorf("denied")),
+			false,
+		},
+		"conflict": {
+			apierrors.NewConflict(resource, "foo_ingress_1", fmt.Errorf("modified")),
+			true,
+		},
+		"server timeout": {
+			apierrors.NewServerTimeout(resource, "update", 1),
+			true,
+		},
+		"unknown error": {
+			fmt.Errorf("connection refused"),
+			true,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "10.0.0.1"
+			fk.StatusWriter = &failingStatusWriter{
+				fail: map[string]bool{"default/foo_ingress_1": true},
+				err:  tc.err,
+			}
+
+			err := fk.sync("just-test")
+			if tc.requeue && err == nil {
+				t.Errorf("expected an error to requeue the sync")
+			}
+			if !tc.requeue && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSyncIngressDeleted(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+
+	// foo_ingress_1 is deleted after it is listed
+	client := fk.Client.(*testclient.Clientset)
+	client.PrependReactor("update", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "foo_ingress_1")
+	})
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.failed != 0 || r.retryable != 0 {
+		t.Errorf("expected no failed Ingress but returned %+v", r)
+	}
+
+	if errs := fk.UpdateErrors(); len(errs) != 0 {
+		t.Errorf("expected no update errors but returned %v", errs)
+	}
+}
+
+func TestSyncNonRetryableError(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	resource := schema.GroupResource{Resource: "pods"}
+
+	testCases := map[string]struct {
+		err          error
+		nonRetryable bool
+	}{
+		"forbidden": {
+			apierrors.NewForbidden(resource, "", fmt.Errorf("denied")),
+			true,
+		},
+		"too many requests": {
+			apierrors.NewTooManyRequests("slow down", 1),
+			false,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.PublishService = ""
+			fk.Client.(*testclient.Clientset).PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tc.err
+			})
+
+			err := fk.sync("just-test")
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if task.IsNonRetryableError(err) != tc.nonRetryable {
+				t.Errorf("returned %v but expected %v", task.IsNonRetryableError(err), tc.nonRetryable)
+			}
+		})
+	}
+}
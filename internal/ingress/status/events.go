@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultStatusEventReason is the default template of the reason of the
+	// Events emitted after the status of an Ingress is updated
+	DefaultStatusEventReason = "StatusUpdated"
+	// DefaultStatusEventMessage is the default template of the message of
+	// the Events emitted after the status of an Ingress is updated
+	DefaultStatusEventMessage = "Ingress status updated to [{{.Addresses}}]"
+)
+
+// statusEventData contains the fields available in the templates of the
+// status Events
+type statusEventData struct {
+	// Namespace is the namespace of the Ingress
+	Namespace string
+	// Name is the name of the Ingress
+	Name string
+	// Addresses is the comma separated list of the published addresses
+	Addresses string
+}
+
+// statusEventTemplates renders the reason and the message of the status Events
+type statusEventTemplates struct {
+	reason  *template.Template
+	message *template.Template
+}
+
+// parseStatusEventTemplates parses the reason and message templates of the
+// status Events, using the defaults if empty. The templates are rendered
+// once to check they only use the available fields.
+func parseStatusEventTemplates(reason, message string) (*statusEventTemplates, error) {
+	if reason == "" {
+		reason = DefaultStatusEventReason
+	}
+	if message == "" {
+		message = DefaultStatusEventMessage
+	}
+
+	reasonTmpl, err := template.New("reason").Parse(reason)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status event reason template %q: %v", reason, err)
+	}
+
+	messageTmpl, err := template.New("message").Parse(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status event message template %q: %v", message, err)
+	}
+
+	t := &statusEventTemplates{
+		reason:  reasonTmpl,
+		message: messageTmpl,
+	}
+
+	sample := statusEventData{Namespace: "default", Name: "example", Addresses: "10.0.0.1"}
+	if _, _, err := t.render(sample); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// render returns the reason and the message of a status Event
+func (t *statusEventTemplates) render(data statusEventData) (string, string, error) {
+	var reason, message bytes.Buffer
+	if err := t.reason.Execute(&reason, data); err != nil {
+		return "", "", fmt.Errorf("rendering status event reason: %v", err)
+	}
+
+	if strings.TrimSpace(reason.String()) == "" {
+		return "", "", fmt.Errorf("empty status event reason")
+	}
+
+	if err := t.message.Execute(&message, data); err != nil {
+		return "", "", fmt.Errorf("rendering status event message: %v", err)
+	}
+
+	return strings.TrimSpace(reason.String()), message.String(), nil
+}
+
+// recordStatusEvent emits an Event in the Ingress after its status is
+// updated. Disabled if there is no Recorder.
+func (s *statusSync) recordStatusEvent(ing *networking.Ingress, addrs []apiv1.LoadBalancerIngress) {
+	if s.Recorder == nil || s.eventTemplates == nil {
+		return
+	}
+
+	reason, message, err := s.eventTemplates.render(statusEventData{
+		Namespace: ing.Namespace,
+		Name:      ing.Name,
+		Addresses: annotationFromStatus(addrs),
+	})
+	if err != nil {
+		klog.Warningf("skipping status event of Ingress %v/%v: %v", ing.Namespace, ing.Name, err)
+		return
+	}
+
+	s.Recorder.Event(ing, apiv1.EventTypeNormal, reason, message)
+}
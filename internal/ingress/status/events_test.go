@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStatusEvent(t *testing.T) {
+	testCases := map[string]struct {
+		reason   string
+		message  string
+		expected string
+	}{
+		"default templates": {
+			expected: "Normal StatusUpdated Ingress status updated to [10.0.0.1,foo.bar.com]",
+		},
+		"custom templates": {
+			reason:   "IngressAddressChanged",
+			message:  "{{.Namespace}}/{{.Name}} now at {{.Addresses}}",
+			expected: "Normal IngressAddressChanged default/foo_ingress now at 10.0.0.1,foo.bar.com",
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			client := testclient.NewSimpleClientset(&networking.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo_ingress",
+					Namespace: apiv1.NamespaceDefault,
+				},
+			})
+
+			templates, err := parseStatusEventTemplates(tc.reason, tc.message)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+
+			fk := buildStatusSync()
+			fk.Client = client
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "foo.bar.com,10.0.0.1"
+			fk.IngressLister = &clientIngressLister{client}
+			fk.Recorder = recorder
+			fk.eventTemplates = templates
+
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if event != tc.expected {
+					t.Errorf("returned %q but expected %q", event, tc.expected)
+				}
+			default:
+				t.Fatalf("expected an event")
+			}
+
+			// no event is emitted when the status does not change
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				t.Errorf("unexpected event %q", event)
+			default:
+			}
+		})
+	}
+}
+
+func TestNewStatusSyncerWithInvalidEventTemplates(t *testing.T) {
+	testCases := map[string]struct {
+		reason  string
+		message string
+	}{
+		"invalid syntax": {message: "{{.Name"},
+		"unknown field":  {message: "{{.Hostname}}"},
+		"empty reason":   {reason: "{{if false}}x{{end}}"},
+		"invalid reason": {reason: "{{.Addresses.Foo}}"},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			_, err := NewStatusSyncer(Config{
+				Client:             buildSimpleClientSet(),
+				IngressLister:      buildIngressLister(),
+				MetricsRegisterer:  prometheus.NewRegistry(),
+				StatusEventReason:  tc.reason,
+				StatusEventMessage: tc.message,
+			})
+			if err == nil {
+				t.Errorf("expected an error creating the status syncer with invalid event templates")
+			}
+		})
+	}
+}
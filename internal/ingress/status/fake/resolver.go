@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake contains in-memory implementations of the interfaces of the
+// status package for tests
+package fake
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeResolver is an AddressResolver returning a fixed list of addresses
+type FakeResolver struct {
+	lock      sync.Mutex
+	addresses []string
+	err       error
+	calls     int
+}
+
+// NewFakeResolver returns a resolver returning the given addresses
+func NewFakeResolver(addresses ...string) *FakeResolver {
+	return &FakeResolver{
+		addresses: addresses,
+	}
+}
+
+// Resolve returns the configured addresses, or the configured error
+func (r *FakeResolver) Resolve(ctx context.Context) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return append([]string{}, r.addresses...), nil
+}
+
+// SetAddresses replaces the addresses returned by the resolver
+func (r *FakeResolver) SetAddresses(addresses ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.addresses = addresses
+}
+
+// SetError makes the resolver return err. A nil error restores the addresses.
+func (r *FakeResolver) SetError(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.err = err
+}
+
+// Calls returns the number of calls to Resolve
+func (r *FakeResolver) Calls() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.calls
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+)
+
+func ExampleFakeResolver() {
+	resolver := NewFakeResolver("10.0.0.1", "lb.example.com")
+
+	addrs, _ := resolver.Resolve(context.TODO())
+	fmt.Println(addrs)
+
+	resolver.SetError(fmt.Errorf("metadata endpoint unavailable"))
+	_, err := resolver.Resolve(context.TODO())
+	fmt.Println(err)
+
+	// Output:
+	// [10.0.0.1 lb.example.com]
+	// metadata endpoint unavailable
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	networking "k8s.io/api/networking/v1beta1"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/ingress/annotations/class"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// listIngresses returns the Ingresses in the configured Namespaces
+func (s *statusSync) listIngresses() []*ingress.Ingress {
+	ings := s.IngressLister.ListIngresses()
+	if len(s.Namespaces) == 0 {
+		return ings
+	}
+
+	result := make([]*ingress.Ingress, 0, len(ings))
+	for _, ing := range ings {
+		if stringInSlice(ing.Namespace, s.Namespaces) {
+			result = append(result, ing)
+		}
+	}
+
+	return result
+}
+
+// validateNamespaces checks the namespaces are watched by the controller
+func validateNamespaces(namespaces []string, watchNamespace string) error {
+	for _, ns := range namespaces {
+		if ns == "" {
+			return fmt.Errorf("empty namespace in the status namespaces")
+		}
+
+		if watchNamespace != "" && ns != watchNamespace {
+			return fmt.Errorf("namespace %v is not watched by the controller (watching only namespace %v)", ns, watchNamespace)
+		}
+	}
+
+	return nil
+}
+
+// validateIngressAllowlist checks the Ingresses of the allowlist are
+// namespace/name pairs
+func validateIngressAllowlist(allowlist []string) error {
+	for _, key := range allowlist {
+		if _, _, err := k8s.ParseNameNS(key); err != nil {
+			return fmt.Errorf("invalid Ingress %q in the status allowlist: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// isClaimed checks if the status of the Ingress should be updated
+// by this controller. The IngressAllowlist replaces the class checks
+// when set. The status-class annotation takes precedence over the
+// class of the Ingress, and the BackendServiceFilter, the
+// LoadBalancerClass and the OwnershipPredicate are checked after the class.
+func (s *statusSync) isClaimed(ing *ingress.Ingress) bool {
+	if len(s.IngressAllowlist) > 0 {
+		if !stringInSlice(fmt.Sprintf("%v/%v", ing.Namespace, ing.Name), s.IngressAllowlist) {
+			return false
+		}
+	} else if statusClass, ok := class.StatusClass(&ing.Ingress); ok {
+		if !class.IsCurrent(statusClass) {
+			return false
+		}
+	} else if isUnclassed(&ing.Ingress) && !s.claimsUnclassed() {
+		return false
+	}
+
+	if s.BackendServiceFilter != "" && !referencesService(&ing.Ingress, s.BackendServiceFilter) {
+		return false
+	}
+
+	if !s.matchesLoadBalancerClass(&ing.Ingress) {
+		return false
+	}
+
+	if s.OwnershipPredicate != nil && !s.OwnershipPredicate(&ing.Ingress) {
+		return false
+	}
+
+	return true
+}
+
+// DefaultClassBehavior is how the status of Ingresses without a class is handled
+type DefaultClassBehavior string
+
+const (
+	// DefaultClassClaim updates the status of the Ingresses without a class
+	DefaultClassClaim DefaultClassBehavior = "Claim"
+	// DefaultClassIgnore skips the Ingresses without a class
+	DefaultClassIgnore DefaultClassBehavior = "Ignore"
+	// DefaultClassClaimIfDefault updates the status of the Ingresses without a
+	// class only when the IngressClass of the controller is the default one
+	DefaultClassClaimIfDefault DefaultClassBehavior = "ClaimIfDefault"
+)
+
+// validateDefaultClassBehavior checks the behavior is one of the known values
+func validateDefaultClassBehavior(behavior DefaultClassBehavior) error {
+	switch behavior {
+	case "", DefaultClassClaim, DefaultClassIgnore, DefaultClassClaimIfDefault:
+		return nil
+	}
+
+	return fmt.Errorf("invalid default class behavior %q (expected %v, %v or %v)", behavior, DefaultClassClaim, DefaultClassIgnore, DefaultClassClaimIfDefault)
+}
+
+// claimsUnclassed returns true if the status of the Ingresses without a
+// class is updated by this controller
+func (s *statusSync) claimsUnclassed() bool {
+	switch s.DefaultClassBehavior {
+	case DefaultClassIgnore:
+		return false
+	case DefaultClassClaimIfDefault:
+		return isDefaultIngressClass()
+	case DefaultClassClaim:
+		return true
+	}
+
+	return !s.OnlyClaimDefaultWhenElected || isDefaultIngressClass()
+}
+
+// referencesService returns true if the default backend or a path of the
+// Ingress routes to the namespace/name service
+func referencesService(ing *networking.Ingress, service string) bool {
+	ns, name, err := k8s.ParseNameNS(service)
+	if err != nil || ing.Namespace != ns {
+		return false
+	}
+
+	if ing.Spec.Backend != nil && ing.Spec.Backend.ServiceName == name {
+		return true
+	}
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.ServiceName == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isUnclassed returns true if the Ingress does not reference an ingress class
+func isUnclassed(ing *networking.Ingress) bool {
+	if ing.Annotations[class.IngressKey] != "" {
+		return false
+	}
+
+	return ing.Spec.IngressClassName == nil || *ing.Spec.IngressClassName == ""
+}
+
+// isDefaultIngressClass returns true if the IngressClass of the controller
+// is marked as the default class of the cluster
+func isDefaultIngressClass() bool {
+	if k8s.IngressClass == nil {
+		return false
+	}
+
+	return k8s.IngressClass.Annotations[networking.AnnotationIsDefaultIngressClass] == "true"
+}
+
+// allowedAddresses removes the hostnames not matching any of the
+// AllowedHostnameSuffixes. IP addresses are not modified.
+func (s *statusSync) allowedAddresses(addrs []string) []string {
+	if len(s.AllowedHostnameSuffixes) == 0 {
+		return addrs
+	}
+
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if net.ParseIP(addr) == nil && !hasAllowedSuffix(addr, s.AllowedHostnameSuffixes) {
+			klog.V(2).InfoS("skipping hostname not allowed in the Ingress status", "hostname", addr)
+			continue
+		}
+
+		result = append(result, addr)
+	}
+
+	return result
+}
+
+// hasAllowedSuffix checks if the hostname is equal to one of the domains
+// in suffixes or is a subdomain of it
+func hasAllowedSuffix(hostname string, suffixes []string) bool {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	for _, suffix := range suffixes {
+		suffix = strings.ToLower(strings.Trim(suffix, "."))
+		if suffix == "" {
+			continue
+		}
+
+		if hostname == suffix || strings.HasSuffix(hostname, "."+suffix) {
+			return true
+		}
+	}
+
+	return false
+}
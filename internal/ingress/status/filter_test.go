@@ -0,0 +1,641 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/class"
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+func TestOnlyClaimDefaultWhenElected(t *testing.T) {
+	defer func() {
+		k8s.IngressClass = nil
+	}()
+
+	buildIngressClass := func(isDefault string) *networking.IngressClass {
+		return &networking.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "nginx",
+				Annotations: map[string]string{
+					networking.AnnotationIsDefaultIngressClass: isDefault,
+				},
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		onlyDefault  bool
+		ingressClass *networking.IngressClass
+		changed      []string
+	}{
+		"option disabled": {
+			false,
+			buildIngressClass("false"),
+			[]string{"default/foo_ingress_1"},
+		},
+		"default controller": {
+			true,
+			buildIngressClass("true"),
+			[]string{"default/foo_ingress_1"},
+		},
+		"non default controller": {
+			true,
+			buildIngressClass("false"),
+			nil,
+		},
+		"controller without IngressClass": {
+			true,
+			nil,
+			nil,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			k8s.IngressClass = tc.ingressClass
+
+			fk := buildStatusSync()
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "10.0.0.1"
+			fk.OnlyClaimDefaultWhenElected = tc.onlyDefault
+
+			r, err := fk.reconcile(context.TODO())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(r.changed, tc.changed) {
+				t.Errorf("returned %v but expected %v", r.changed, tc.changed)
+			}
+		})
+	}
+}
+
+func TestIsUnclassed(t *testing.T) {
+	className := "nginx"
+	emptyClassName := ""
+
+	fooTests := []struct {
+		title string
+		ing   *networking.Ingress
+		er    bool
+	}{
+		{"without class", &networking.Ingress{}, true},
+		{"empty annotation", &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kubernetes.io/ingress.class": ""}}}, true},
+		{"annotation", &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kubernetes.io/ingress.class": "nginx"}}}, false},
+		{"empty class name", &networking.Ingress{Spec: networking.IngressSpec{IngressClassName: &emptyClassName}}, true},
+		{"class name", &networking.Ingress{Spec: networking.IngressSpec{IngressClassName: &className}}, false},
+	}
+
+	for _, fooTest := range fooTests {
+		r := isUnclassed(fooTest.ing)
+		if r != fooTest.er {
+			t.Errorf("%v: returned %v but expected %v", fooTest.title, r, fooTest.er)
+		}
+	}
+}
+
+func TestDefaultClassBehavior(t *testing.T) {
+	defer func() {
+		k8s.IngressClass = nil
+	}()
+
+	buildIngressClass := func(isDefault string) *networking.IngressClass {
+		return &networking.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "nginx",
+				Annotations: map[string]string{
+					networking.AnnotationIsDefaultIngressClass: isDefault,
+				},
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		behavior     DefaultClassBehavior
+		onlyDefault  bool
+		ingressClass *networking.IngressClass
+		changed      []string
+	}{
+		"unset": {
+			"",
+			false,
+			buildIngressClass("false"),
+			[]string{"default/foo_ingress_1"},
+		},
+		"claim": {
+			DefaultClassClaim,
+			false,
+			buildIngressClass("false"),
+			[]string{"default/foo_ingress_1"},
+		},
+		"claim replaces OnlyClaimDefaultWhenElected": {
+			DefaultClassClaim,
+			true,
+			buildIngressClass("false"),
+			[]string{"default/foo_ingress_1"},
+		},
+		"ignore": {
+			DefaultClassIgnore,
+			false,
+			buildIngressClass("true"),
+			nil,
+		},
+		"claim if default in the default controller": {
+			DefaultClassClaimIfDefault,
+			false,
+			buildIngressClass("true"),
+			[]string{"default/foo_ingress_1"},
+		},
+		"claim if default in a non default controller": {
+			DefaultClassClaimIfDefault,
+			false,
+			buildIngressClass("false"),
+			nil,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			k8s.IngressClass = tc.ingressClass
+
+			fk := buildStatusSync()
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "10.0.0.1"
+			fk.DefaultClassBehavior = tc.behavior
+			fk.OnlyClaimDefaultWhenElected = tc.onlyDefault
+
+			r, err := fk.reconcile(context.TODO())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(r.changed, tc.changed) {
+				t.Errorf("returned %v but expected %v", r.changed, tc.changed)
+			}
+		})
+	}
+}
+
+func TestValidateDefaultClassBehavior(t *testing.T) {
+	testCases := []struct {
+		behavior DefaultClassBehavior
+		valid    bool
+	}{
+		{"", true},
+		{DefaultClassClaim, true},
+		{DefaultClassIgnore, true},
+		{DefaultClassClaimIfDefault, true},
+		{"claim", false},
+		{"Always", false},
+	}
+
+	for _, tc := range testCases {
+		err := validateDefaultClassBehavior(tc.behavior)
+		if (err == nil) != tc.valid {
+			t.Errorf("%v: unexpected error %v", tc.behavior, err)
+		}
+	}
+}
+
+func TestAllowedHostnameSuffixes(t *testing.T) {
+	testCases := map[string]struct {
+		suffixes []string
+		expected []apiv1.LoadBalancerIngress
+	}{
+		"without allowed suffixes": {
+			nil,
+			[]apiv1.LoadBalancerIngress{
+				{IP: "10.0.0.1"},
+				{Hostname: "lb.example.com"},
+				{Hostname: "lb.internal.local"},
+				{Hostname: "notexample.com"},
+			},
+		},
+		"allowed suffixes": {
+			[]string{".example.com", "example.org"},
+			[]apiv1.LoadBalancerIngress{
+				{IP: "10.0.0.1"},
+				{Hostname: "lb.example.com"},
+			},
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "lb.example.com,10.0.0.1,lb.internal.local,notexample.com"
+			fk.AllowedHostnameSuffixes = tc.suffixes
+
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, tc.expected) {
+				t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, tc.expected)
+			}
+		})
+	}
+}
+
+func TestHasAllowedSuffix(t *testing.T) {
+	suffixes := []string{"example.com", ".example.org."}
+
+	fooTests := []struct {
+		hostname string
+		er       bool
+	}{
+		{"example.com", true},
+		{"lb.example.com", true},
+		{"LB.Example.COM", true},
+		{"lb.example.org.", true},
+		{"notexample.com", false},
+		{"example.com.evil.io", false},
+		{"lb.internal", false},
+	}
+
+	for _, fooTest := range fooTests {
+		r := hasAllowedSuffix(fooTest.hostname, suffixes)
+		if r != fooTest.er {
+			t.Errorf("%v: returned %v but expected %v", fooTest.hostname, r, fooTest.er)
+		}
+	}
+}
+
+// buildMultiNamespaceClientSet returns a client with one Ingress in
+// each of the namespaces ns-a, ns-b and ns-c
+func buildMultiNamespaceClientSet() *testclient.Clientset {
+	objects := []runtime.Object{}
+	for _, ns := range []string{"ns-a", "ns-b", "ns-c"} {
+		objects = append(objects, &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_ingress",
+				Namespace: ns,
+			},
+		})
+	}
+
+	return testclient.NewSimpleClientset(objects...)
+}
+
+func TestNamespaces(t *testing.T) {
+	client := buildMultiNamespaceClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.Namespaces = []string{"ns-a", "ns-b"}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 2 || r.skipped != 0 {
+		t.Errorf("expected two updated Ingresses but returned %+v", r)
+	}
+
+	expected := map[string]int{"ns-a": 1, "ns-b": 1, "ns-c": 0}
+	for ns, n := range expected {
+		ing, err := client.NetworkingV1beta1().Ingresses(ns).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ing.Status.LoadBalancer.Ingress) != n {
+			t.Errorf("%v: expected %v addresses but returned %v", ns, n, ing.Status.LoadBalancer.Ingress)
+		}
+	}
+}
+
+func TestValidateNamespaces(t *testing.T) {
+	testCases := []struct {
+		namespaces     []string
+		watchNamespace string
+		valid          bool
+	}{
+		{nil, "", true},
+		{nil, "ns-a", true},
+		{[]string{"ns-a", "ns-b"}, "", true},
+		{[]string{"ns-a"}, "ns-a", true},
+		{[]string{"ns-a", "ns-b"}, "ns-a", false},
+		{[]string{""}, "", false},
+	}
+
+	for _, tc := range testCases {
+		err := validateNamespaces(tc.namespaces, tc.watchNamespace)
+		if (err == nil) != tc.valid {
+			t.Errorf("%v (watching %q): unexpected error %v", tc.namespaces, tc.watchNamespace, err)
+		}
+	}
+}
+
+func TestStatusClassOverride(t *testing.T) {
+	ic := class.IngressClass
+	defer func() {
+		class.IngressClass = ic
+		k8s.IngressClass = nil
+	}()
+	class.IngressClass = "nginx"
+	k8s.IngressClass = nil
+
+	newIngress := func(name, routingClass, statusClass string) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: apiv1.NamespaceDefault,
+				Annotations: map[string]string{
+					class.IngressKey: routingClass,
+					parser.GetAnnotationWithPrefix(class.StatusKey): statusClass,
+				},
+			},
+		}
+	}
+
+	client := testclient.NewSimpleClientset(
+		// routed by this controller, status owned by another one
+		newIngress("foo_routed", "nginx", "other"),
+		// routed by another controller, status owned by this one
+		newIngress("foo_status_owned", "other", "nginx"),
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 || r.skipped != 1 {
+		t.Errorf("expected one updated and one skipped Ingress but returned %+v", r)
+	}
+
+	for name, expected := range map[string]int{"foo_routed": 0, "foo_status_owned": 1} {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ing.Status.LoadBalancer.Ingress) != expected {
+			t.Errorf("%v: expected %v addresses but returned %v", name, expected, ing.Status.LoadBalancer.Ingress)
+		}
+	}
+}
+
+func TestOwnershipPredicate(t *testing.T) {
+	const finalizer = "example.com/status-managed-externally"
+
+	client := testclient.NewSimpleClientset(
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_ingress",
+				Namespace: apiv1.NamespaceDefault,
+			},
+		},
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "foo_ingress_finalizer",
+				Namespace:  apiv1.NamespaceDefault,
+				Finalizers: []string{finalizer},
+			},
+		},
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.OwnershipPredicate = func(ing *networking.Ingress) bool {
+		return !stringInSlice(finalizer, ing.Finalizers)
+	}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 || r.skipped != 1 {
+		t.Errorf("expected one updated and one skipped Ingress but returned %+v", r)
+	}
+
+	for name, expected := range map[string]int{"foo_ingress": 1, "foo_ingress_finalizer": 0} {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ing.Status.LoadBalancer.Ingress) != expected {
+			t.Errorf("%v: expected %v addresses but returned %v", name, expected, ing.Status.LoadBalancer.Ingress)
+		}
+	}
+
+	// a nil predicate claims all the Ingresses
+	fk.OwnershipPredicate = nil
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 || r.skipped != 1 {
+		t.Errorf("expected one updated and one skipped Ingress but returned %+v", r)
+	}
+}
+
+func buildAllowlistClientSet() *testclient.Clientset {
+	newIngress := func(namespace, name, ingressClass string) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					class.IngressKey: ingressClass,
+				},
+			},
+		}
+	}
+
+	return testclient.NewSimpleClientset(
+		newIngress(apiv1.NamespaceDefault, "foo_allowed", "nginx"),
+		newIngress("ns-a", "foo_allowed", "other"),
+		newIngress(apiv1.NamespaceDefault, "foo_not_allowed", "nginx"),
+	)
+}
+
+func TestIngressAllowlist(t *testing.T) {
+	ic := class.IngressClass
+	defer func() {
+		class.IngressClass = ic
+	}()
+	class.IngressClass = "nginx"
+
+	client := buildAllowlistClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.IngressAllowlist = []string{"default/foo_allowed", "ns-a/foo_allowed"}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 2 || r.skipped != 1 {
+		t.Errorf("expected two updated and one skipped Ingress but returned %+v", r)
+	}
+
+	expected := map[string]int{
+		"default/foo_allowed":     1,
+		"ns-a/foo_allowed":        1,
+		"default/foo_not_allowed": 0,
+	}
+	for key, n := range expected {
+		ns, name, _ := k8s.ParseNameNS(key)
+		ing, err := client.NetworkingV1beta1().Ingresses(ns).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ing.Status.LoadBalancer.Ingress) != n {
+			t.Errorf("%v: expected %v addresses but returned %v", key, n, ing.Status.LoadBalancer.Ingress)
+		}
+	}
+}
+
+func TestValidateIngressAllowlist(t *testing.T) {
+	testCases := []struct {
+		allowlist []string
+		valid     bool
+	}{
+		{nil, true},
+		{[]string{"default/foo", "ns-a/bar"}, true},
+		{[]string{"foo"}, false},
+		{[]string{"default/foo", ""}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateIngressAllowlist(tc.allowlist)
+		if (err == nil) != tc.valid {
+			t.Errorf("%v: unexpected error %v", tc.allowlist, err)
+		}
+	}
+}
+
+// buildBackendIngress returns an Ingress routing the path / to the services
+func buildBackendIngress(namespace, name string, services ...string) *networking.Ingress {
+	paths := []networking.HTTPIngressPath{}
+	for _, svc := range services {
+		paths = append(paths, networking.HTTPIngressPath{
+			Path:    "/",
+			Backend: networking.IngressBackend{ServiceName: svc, ServicePort: intstr.FromInt(80)},
+		})
+	}
+
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{
+				{
+					Host: "foo.bar",
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{Paths: paths},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBackendServiceFilter(t *testing.T) {
+	defaultBackend := buildBackendIngress(apiv1.NamespaceDefault, "foo_default_backend")
+	defaultBackend.Spec.Backend = &networking.IngressBackend{ServiceName: "app", ServicePort: intstr.FromInt(80)}
+
+	client := testclient.NewSimpleClientset(
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_app", "app"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_app_and_other", "other", "app"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_other", "other"),
+		buildBackendIngress("ns-a", "foo_app", "app"),
+		defaultBackend,
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_without_http",
+				Namespace: apiv1.NamespaceDefault,
+			},
+			Spec: networking.IngressSpec{
+				Rules: []networking.IngressRule{{Host: "foo.bar"}},
+			},
+		},
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.BackendServiceFilter = "default/app"
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"default/foo_app", "default/foo_app_and_other", "default/foo_default_backend"}
+	if !reflect.DeepEqual(r.changed, expected) {
+		t.Errorf("returned %v but expected %v", r.changed, expected)
+	}
+
+	// the service of the same name in another namespace is not referenced
+	ing, err := client.NetworkingV1beta1().Ingresses("ns-a").Get(context.TODO(), "foo_app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ing.Status.LoadBalancer.Ingress) != 0 {
+		t.Errorf("expected an empty status but returned %v", ing.Status.LoadBalancer.Ingress)
+	}
+}
+
+func TestInvalidBackendServiceFilter(t *testing.T) {
+	_, err := NewStatusSyncer(Config{
+		Client:               buildSimpleClientSet(),
+		IngressLister:        buildIngressLister(),
+		BackendServiceFilter: "app",
+	})
+	if err == nil {
+		t.Errorf("expected an error with a service without namespace")
+	}
+}
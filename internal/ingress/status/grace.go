@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// emptyAddressGraceAnnotation is the annotation, without prefix, overriding
+// the EmptyAddressGrace of an Ingress
+const emptyAddressGraceAnnotation = "empty-address-grace"
+
+// emptyAddressTracker records since when the published addresses are empty
+type emptyAddressTracker struct {
+	lock sync.Mutex
+
+	clock clock.Clock
+
+	// since is the time the addresses became empty, zero if not empty
+	since time.Time
+}
+
+func newEmptyAddressTracker(c clock.Clock) *emptyAddressTracker {
+	return &emptyAddressTracker{clock: c}
+}
+
+// observe records if the addresses published in a sync are empty
+func (t *emptyAddressTracker) observe(empty bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch {
+	case !empty:
+		t.since = time.Time{}
+	case t.since.IsZero():
+		t.since = t.clock.Now()
+	}
+}
+
+// emptyFor returns how long the published addresses have been empty.
+// Returns false if they are not empty.
+func (t *emptyAddressTracker) emptyFor() (time.Duration, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.since.IsZero() {
+		return 0, false
+	}
+
+	return t.clock.Since(t.since), true
+}
+
+// ingressEmptyAddressGrace returns the time the status of the Ingress is
+// kept after the addresses become empty: the value of the
+// empty-address-grace annotation, or EmptyAddressGrace
+func (s *statusSync) ingressEmptyAddressGrace(ing *networking.Ingress) time.Duration {
+	value, ok := ing.Annotations[parser.GetAnnotationWithPrefix(emptyAddressGraceAnnotation)]
+	if !ok {
+		return s.EmptyAddressGrace
+	}
+
+	grace, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || grace < 0 {
+		klog.Warningf("ignoring invalid empty address grace %q of Ingress %v/%v", value, ing.Namespace, ing.Name)
+		return s.EmptyAddressGrace
+	}
+
+	return grace
+}
+
+// inEmptyAddressGrace checks if the empty addresses are not written yet in
+// the status of the Ingress
+func (s *statusSync) inEmptyAddressGrace(ing *networking.Ingress) bool {
+	if s.emptyAddresses == nil {
+		return false
+	}
+
+	emptyFor, ok := s.emptyAddresses.emptyFor()
+	if !ok {
+		return false
+	}
+
+	return emptyFor < s.ingressEmptyAddressGrace(ing)
+}
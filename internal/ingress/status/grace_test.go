@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+func buildGraceIngress(name string, annotations map[string]string) *networking.Ingress {
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   apiv1.NamespaceDefault,
+			Annotations: annotations,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+}
+
+func TestEmptyAddressGrace(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels:    map[string]string{"app": "no-pods"},
+		},
+	}
+
+	client := testclient.NewSimpleClientset(
+		buildGraceIngress("foo_global", nil),
+		buildGraceIngress("foo_immediate", map[string]string{
+			parser.GetAnnotationWithPrefix(emptyAddressGraceAnnotation): "0s",
+		}),
+	)
+
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	// no pod of the controller is running
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.IngressLister = &clientIngressLister{client}
+	fk.EmptyAddressGrace = time.Minute
+	fk.emptyAddresses = newEmptyAddressTracker(fakeClock)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status := getIngressStatus(t, client, "foo_immediate"); len(status) != 0 {
+		t.Errorf("expected an empty status but returned %v", status)
+	}
+	if status := getIngressStatus(t, client, "foo_global"); len(status) != 1 {
+		t.Errorf("expected the status to be kept during the grace period but returned %v", status)
+	}
+
+	fakeClock.Step(2 * time.Minute)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status := getIngressStatus(t, client, "foo_global"); len(status) != 0 {
+		t.Errorf("expected an empty status after the grace period but returned %v", status)
+	}
+}
+
+func TestIngressEmptyAddressGrace(t *testing.T) {
+	fk := buildStatusSync()
+	fk.EmptyAddressGrace = time.Minute
+
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    time.Duration
+	}{
+		"global":   {nil, time.Minute},
+		"override": {map[string]string{parser.GetAnnotationWithPrefix(emptyAddressGraceAnnotation): "10s"}, 10 * time.Second},
+		"zero":     {map[string]string{parser.GetAnnotationWithPrefix(emptyAddressGraceAnnotation): "0s"}, 0},
+		"invalid":  {map[string]string{parser.GetAnnotationWithPrefix(emptyAddressGraceAnnotation): "soon"}, time.Minute},
+		"negative": {map[string]string{parser.GetAnnotationWithPrefix(emptyAddressGraceAnnotation): "-1s"}, time.Minute},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			ing := buildGraceIngress("foo", tc.annotations)
+			if grace := fk.ingressEmptyAddressGrace(ing); grace != tc.expected {
+				t.Errorf("returned %v but expected %v", grace, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	pool "gopkg.in/go-playground/pool.v3"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultHealthCheckTimeout is the timeout of the address probes when
+	// AddressHealthCheck.Timeout is not set
+	defaultHealthCheckTimeout = 2 * time.Second
+
+	// maxConcurrentHealthChecks is the max number of addresses probed in parallel
+	maxConcurrentHealthChecks = 10
+)
+
+// AddressHealthCheck configures a TCP probe of the addresses before
+// publishing them in the Ingress status
+type AddressHealthCheck struct {
+	// Port is the TCP port to connect to in each address
+	Port int
+	// Timeout is the max duration of each connection attempt
+	Timeout time.Duration
+}
+
+// dialFunc opens a connection to address with the given timeout
+type dialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
+
+// healthyAddresses returns the addresses accepting TCP connections in the
+// health check port. If no address is reachable all of them are returned,
+// to avoid removing the status of the Ingresses due to a network problem
+// in the controller.
+func (s *statusSync) healthyAddresses(addrs []string) []string {
+	hc := s.AddressHealthCheck
+	if hc == nil || len(addrs) == 0 {
+		return addrs
+	}
+
+	timeout := hc.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	dial := s.dial
+	if dial == nil {
+		dial = net.DialTimeout
+	}
+
+	p := pool.NewLimited(maxConcurrentHealthChecks)
+	defer p.Close()
+
+	batch := p.Batch()
+	for _, addr := range addrs {
+		batch.Queue(probeAddress(dial, addr, hc.Port, timeout))
+	}
+	batch.QueueComplete()
+
+	healthy := map[string]bool{}
+	for wu := range batch.Results() {
+		if addr, ok := wu.Value().(string); ok && wu.Error() == nil {
+			healthy[addr] = true
+		}
+	}
+
+	if len(healthy) == 0 {
+		klog.Warningf("none of the addresses %v accepts connections in port %v, publishing all of them", addrs, hc.Port)
+		return addrs
+	}
+
+	result := make([]string, 0, len(healthy))
+	for _, addr := range addrs {
+		if !healthy[addr] {
+			klog.Warningf("address %v does not accept connections in port %v, removing it from the Ingress status", addr, hc.Port)
+			continue
+		}
+
+		result = append(result, addr)
+	}
+
+	return result
+}
+
+func probeAddress(dial dialFunc, addr string, port int, timeout time.Duration) pool.WorkFunc {
+	return func(wu pool.WorkUnit) (interface{}, error) {
+		if wu.IsCancelled() {
+			return nil, nil
+		}
+
+		conn, err := dial("tcp", net.JoinHostPort(addr, strconv.Itoa(port)), timeout)
+		if err != nil {
+			return addr, err
+		}
+
+		conn.Close()
+		return addr, nil
+	}
+}
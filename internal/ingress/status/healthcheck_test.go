@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeDialer fails the connections to the addresses in down
+type fakeDialer struct {
+	sync.Mutex
+
+	down   map[string]bool
+	dialed []string
+}
+
+func (d *fakeDialer) dial(network, address string, timeout time.Duration) (net.Conn, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.dialed = append(d.dialed, address)
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.down[host] {
+		return nil, fmt.Errorf("dial %v %v: connection refused", network, address)
+	}
+
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+func TestHealthyAddresses(t *testing.T) {
+	testCases := map[string]struct {
+		healthCheck *AddressHealthCheck
+		down        map[string]bool
+		addrs       []string
+		expected    []string
+	}{
+		"health check disabled": {
+			nil,
+			map[string]bool{"10.0.0.1": true},
+			[]string{"10.0.0.1", "10.0.0.2"},
+			[]string{"10.0.0.1", "10.0.0.2"},
+		},
+		"all addresses reachable": {
+			&AddressHealthCheck{Port: 80},
+			map[string]bool{},
+			[]string{"10.0.0.1", "10.0.0.2"},
+			[]string{"10.0.0.1", "10.0.0.2"},
+		},
+		"unreachable addresses": {
+			&AddressHealthCheck{Port: 80},
+			map[string]bool{"10.0.0.1": true, "foo.bar.com": true},
+			[]string{"10.0.0.1", "10.0.0.2", "foo.bar.com", "10.0.0.3"},
+			[]string{"10.0.0.2", "10.0.0.3"},
+		},
+		"no reachable address": {
+			&AddressHealthCheck{Port: 80},
+			map[string]bool{"10.0.0.1": true, "10.0.0.2": true},
+			[]string{"10.0.0.1", "10.0.0.2"},
+			[]string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			dialer := &fakeDialer{down: tc.down}
+
+			fk := buildStatusSync()
+			fk.AddressHealthCheck = tc.healthCheck
+			fk.dial = dialer.dial
+
+			ra := fk.healthyAddresses(tc.addrs)
+			if !reflect.DeepEqual(ra, tc.expected) {
+				t.Errorf("returned %v but expected %v", ra, tc.expected)
+			}
+
+			if tc.healthCheck == nil && len(dialer.dialed) != 0 {
+				t.Errorf("unexpected probes %v with the health check disabled", dialer.dialed)
+			}
+		})
+	}
+}
+
+func TestReconcileWithAddressHealthCheck(t *testing.T) {
+	dialer := &fakeDialer{down: map[string]bool{"10.0.0.2": true}}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1,10.0.0.2"
+	fk.AddressHealthCheck = &AddressHealthCheck{Port: 443}
+	fk.dial = dialer.dial
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	expectedDials := map[string]bool{"10.0.0.1:443": true, "10.0.0.2:443": true}
+	for _, addr := range dialer.dialed {
+		if !expectedDials[addr] {
+			t.Errorf("unexpected probe of %v", addr)
+		}
+	}
+}
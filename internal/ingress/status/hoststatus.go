@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// hostStatusAnnotationPrefix is prepended to the annotations prefix in the
+// name of the annotations containing the addresses published for each host
+const hostStatusAnnotationPrefix = "host-status"
+
+// hostStatusAnnotationKey returns the name of the annotation containing the
+// addresses published for the host
+func hostStatusAnnotationKey(host string) string {
+	return fmt.Sprintf("%v.%v/%v", hostStatusAnnotationPrefix, parser.AnnotationsPrefix, host)
+}
+
+// hostStatusAnnotations returns the annotations with the addresses published
+// for each host of the rules of the Ingress, skipping the ones up to date,
+// and the annotations of the hosts no longer in the rules to remove.
+// The addresses are a comma separated list of IPs and hostnames.
+func hostStatusAnnotations(ing *networking.Ingress, addrs []apiv1.LoadBalancerIngress) (map[string]string, []string) {
+	values := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr.IP != "" {
+			values = append(values, addr.IP)
+		} else if addr.Hostname != "" {
+			values = append(values, addr.Hostname)
+		}
+	}
+	value := strings.Join(values, ",")
+
+	annotations := map[string]string{}
+	hosts := sets.NewString()
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+
+		key := hostStatusAnnotationKey(rule.Host)
+		if len(validation.IsDNS1123Subdomain(rule.Host)) > 0 || len(validation.IsQualifiedName(key)) > 0 {
+			klog.Warningf("skipping host status of invalid host %q in Ingress %v/%v", rule.Host, ing.Namespace, ing.Name)
+			continue
+		}
+
+		hosts.Insert(key)
+		if current, ok := ing.Annotations[key]; ok && current == value {
+			continue
+		}
+
+		annotations[key] = value
+	}
+
+	var removed []string
+	prefix := hostStatusAnnotationKey("")
+	for key := range ing.Annotations {
+		if strings.HasPrefix(key, prefix) && !hosts.Has(key) {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+
+	return annotations, removed
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+// buildMultiHostIngress returns an Ingress with a rule for each host
+func buildMultiHostIngress(hosts ...string) *networking.Ingress {
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_multi_host",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	for _, host := range hosts {
+		ing.Spec.Rules = append(ing.Spec.Rules, networking.IngressRule{Host: host})
+	}
+
+	return ing
+}
+
+func TestPublishHostStatus(t *testing.T) {
+	longHost := strings.Repeat("a", 60) + ".example.com"
+	client := testclient.NewSimpleClientset(buildMultiHostIngress(
+		"foo.example.com",
+		"bar.example.com",
+		"",
+		"*.example.com",
+		longHost,
+	))
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1,lb.example.com"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishHostStatus = true
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_multi_host", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"host-status.nginx.ingress.kubernetes.io/foo.example.com": "10.0.0.1,lb.example.com",
+		"host-status.nginx.ingress.kubernetes.io/bar.example.com": "10.0.0.1,lb.example.com",
+	}
+	for key, value := range ing.Annotations {
+		if strings.HasPrefix(key, hostStatusAnnotationPrefix) && expected[key] != value {
+			t.Errorf("unexpected annotation %v: %v", key, value)
+		}
+	}
+	for key, value := range expected {
+		if ing.Annotations[key] != value {
+			t.Errorf("returned %v but expected %v in annotation %v", ing.Annotations[key], value, key)
+		}
+	}
+
+	// the annotations up to date are not written again
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.skipped != 1 {
+		t.Errorf("expected one skipped Ingress but returned %+v", r)
+	}
+
+	// the annotation of a host removed from the rules is removed
+	ing.Spec.Rules = ing.Spec.Rules[:1]
+	if _, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Update(context.TODO(), ing, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_multi_host", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ing.Annotations["host-status.nginx.ingress.kubernetes.io/bar.example.com"]; ok {
+		t.Errorf("expected the annotation of the removed host bar.example.com to be removed")
+	}
+	if value := ing.Annotations["host-status.nginx.ingress.kubernetes.io/foo.example.com"]; value != "10.0.0.1,lb.example.com" {
+		t.Errorf("returned %v but expected %v", value, "10.0.0.1,lb.example.com")
+	}
+}
+
+func TestHostStatusAnnotations(t *testing.T) {
+	ing := buildMultiHostIngress("foo.example.com", "bar.example.com")
+	ing.Annotations = map[string]string{
+		hostStatusAnnotationKey("foo.example.com"):   "10.0.0.1",
+		hostStatusAnnotationKey("bar.example.com"):   "10.0.0.2",
+		hostStatusAnnotationKey("old.example.com"):   "10.0.0.1",
+		"nginx.ingress.kubernetes.io/rewrite-target": "/",
+	}
+
+	annotations, removed := hostStatusAnnotations(ing, []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+
+	expected := map[string]string{hostStatusAnnotationKey("bar.example.com"): "10.0.0.1"}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("returned %v but expected %v", annotations, expected)
+	}
+
+	// only the host-status annotations of the hosts not in the rules are removed
+	expectedRemoved := []string{hostStatusAnnotationKey("old.example.com")}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("returned %v but expected %v", removed, expectedRemoved)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// enqueuedKey is the context key of the time the synced element was
+// added to the queue
+type enqueuedKey struct{}
+
+// withEnqueued returns a copy of ctx with the time the queue element
+// was enqueued. The context is not modified if the time is unknown.
+func withEnqueued(ctx context.Context, key interface{}) context.Context {
+	item, ok := key.(task.Element)
+	if !ok || item.Enqueued == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, enqueuedKey{}, time.Unix(0, item.Enqueued))
+}
+
+// syncLatency returns the time elapsed since the synced element was
+// enqueued, or false if the time it was enqueued is unknown
+func (s *statusSync) syncLatency(ctx context.Context) (time.Duration, bool) {
+	enqueued, ok := ctx.Value(enqueuedKey{}).(time.Time)
+	if !ok {
+		return 0, false
+	}
+
+	c := s.clock
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	return c.Since(enqueued), true
+}
+
+// logSyncLatency logs the time elapsed from the enqueue of the sync to
+// the update of the status of the Ingress key
+func (s *statusSync) logSyncLatency(ctx context.Context, key string) {
+	latency, ok := s.syncLatency(ctx)
+	if !ok {
+		return
+	}
+
+	klog.InfoS("updated Ingress status", "ingress", key, "latency", latency)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// logBuffer is a bytes.Buffer safe for concurrent use, to capture the
+// logs written by the goroutines of other tests
+type logBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buf.String()
+}
+
+func TestSyncLatency(t *testing.T) {
+	var buf logBuffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&buf)
+	defer func() {
+		klog.SetOutput(os.Stderr)
+		klog.LogToStderr(true)
+	}()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.clock = fakeClock
+
+	key := task.Element{
+		Key:      "sync status",
+		Enqueued: fakeClock.Now().UnixNano(),
+	}
+
+	fakeClock.Step(3 * time.Second)
+
+	latency, ok := fk.syncLatency(withEnqueued(context.TODO(), key))
+	if !ok || latency != 3*time.Second {
+		t.Errorf("returned %v but expected %v", latency, 3*time.Second)
+	}
+
+	if err := fk.sync(key); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	klog.Flush()
+
+	expected := `"updated Ingress status" ingress="default/foo_ingress_1" latency="3s"`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the log to contain %v but returned %v", expected, buf.String())
+	}
+
+	// the latency is unknown for elements without an enqueue time
+	if _, ok := fk.syncLatency(withEnqueued(context.TODO(), "sync status")); ok {
+		t.Errorf("expected an unknown latency for an element without an enqueue time")
+	}
+}
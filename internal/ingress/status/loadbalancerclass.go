@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+var (
+	servicesResource  = apiv1.SchemeGroupVersion.WithResource("services")
+	ingressesResource = networking.SchemeGroupVersion.WithResource("ingresses")
+)
+
+// matchesLoadBalancerClass returns true if LoadBalancerClass is not set or
+// it is the loadBalancerClass of the Ingress or, when the Ingress has no
+// class, of one of the Services it routes to
+func (s *statusSync) matchesLoadBalancerClass(ing *networking.Ingress) bool {
+	if s.LoadBalancerClass == "" {
+		return true
+	}
+
+	if lbClass, ok := s.loadBalancerClass(ingressesResource, ing.Namespace, ing.Name); ok {
+		return lbClass == s.LoadBalancerClass
+	}
+
+	for _, name := range backendServices(ing) {
+		if lbClass, ok := s.loadBalancerClass(servicesResource, ing.Namespace, name); ok && lbClass == s.LoadBalancerClass {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadBalancerClass returns the spec.loadBalancerClass of the object. The
+// object is read with the DynamicClient, as the field is not available in
+// the typed API. Returns false if the object has no class or cannot be read.
+func (s *statusSync) loadBalancerClass(resource schema.GroupVersionResource, namespace, name string) (string, bool) {
+	obj, err := s.DynamicClient.Resource(resource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Warningf("error obtaining the loadBalancerClass of %v %v/%v: %v", resource.Resource, namespace, name, err)
+		}
+
+		return "", false
+	}
+
+	lbClass, found, err := unstructured.NestedString(obj.Object, "spec", "loadBalancerClass")
+	if err != nil || !found || lbClass == "" {
+		return "", false
+	}
+
+	return lbClass, true
+}
+
+// backendServices returns the names of the services of the default backend
+// and the paths of the Ingress, without duplicates
+func backendServices(ing *networking.Ingress) []string {
+	names := []string{}
+	if ing.Spec.Backend != nil && ing.Spec.Backend.ServiceName != "" {
+		names = append(names, ing.Spec.Backend.ServiceName)
+	}
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.ServiceName != "" && !stringInSlice(path.Backend.ServiceName, names) {
+				names = append(names, path.Backend.ServiceName)
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+// buildLoadBalancerClassObject returns an unstructured object of the given
+// kind with the loadBalancerClass, or without it if empty
+func buildLoadBalancerClassObject(apiVersion, kind, name, lbClass string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": apiv1.NamespaceDefault,
+		},
+		"spec": map[string]interface{}{},
+	}}
+
+	if lbClass != "" {
+		obj.Object["spec"].(map[string]interface{})["loadBalancerClass"] = lbClass
+	}
+
+	return obj
+}
+
+func TestLoadBalancerClass(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_class_a", "svc_a"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_class_b", "svc_b"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_class_b_and_a", "svc_b", "svc_a"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_ingress_class_a", "svc_b"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_ingress_class_b", "svc_a"),
+		buildBackendIngress(apiv1.NamespaceDefault, "foo_without_class", "svc_without_class"),
+	)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		buildLoadBalancerClassObject("v1", "Service", "svc_a", "class-a"),
+		buildLoadBalancerClassObject("v1", "Service", "svc_b", "class-b"),
+		buildLoadBalancerClassObject("v1", "Service", "svc_without_class", ""),
+		// the class of the Ingress takes precedence over the one of its services
+		buildLoadBalancerClassObject("networking.k8s.io/v1beta1", "Ingress", "foo_ingress_class_a", "class-a"),
+		buildLoadBalancerClassObject("networking.k8s.io/v1beta1", "Ingress", "foo_ingress_class_b", "class-b"),
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.DynamicClient = dynamicClient
+	fk.LoadBalancerClass = "class-a"
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"default/foo_class_a", "default/foo_class_b_and_a", "default/foo_ingress_class_a"}
+	if !reflect.DeepEqual(r.changed, expected) {
+		t.Errorf("returned %v but expected %v", r.changed, expected)
+	}
+
+	// without class all the Ingresses are updated
+	fk.LoadBalancerClass = ""
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []string{"default/foo_class_b", "default/foo_ingress_class_b", "default/foo_without_class"}
+	if !reflect.DeepEqual(r.changed, expected) {
+		t.Errorf("returned %v but expected %v", r.changed, expected)
+	}
+}
+
+func TestLoadBalancerClassWithoutDynamicClient(t *testing.T) {
+	_, err := NewStatusSyncer(Config{
+		Client:            buildSimpleClientSet(),
+		IngressLister:     buildIngressLister(),
+		LoadBalancerClass: "class-a",
+	})
+	if err == nil {
+		t.Errorf("expected an error with a load balancer class without dynamic client")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/class"
+	"k8s.io/ingress-nginx/internal/ingress/metric/collectors"
+	"k8s.io/ingress-nginx/internal/k8s"
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// statusMetrics contains the metrics of the status synchronization. The
+// leadership is reported by the leader_election_status gauge of the
+// controller metrics, updated by the same election.
+type statusMetrics struct {
+	// queueLatency is the time the elements wait in the sync queue
+	queueLatency prometheus.Observer
+	// updates counts the Ingresses updated, skipped and failed in the syncs
+	updates *prometheus.CounterVec
+	// lastSyncDuration is the duration of the last sync
+	lastSyncDuration prometheus.Gauge
+}
+
+// newStatusMetrics registers the status metrics in reg, reusing the
+// collectors already registered by another syncer. The depth of the
+// queue is reported by the last registered syncer.
+func newStatusMetrics(reg prometheus.Registerer, queue *task.Queue) *statusMetrics {
+	constLabels := statusConstLabels()
+
+	queueLatency := registerOrReuse(reg, prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace:   collectors.PrometheusNamespace,
+			Name:        "ingress_status_queue_latency_seconds",
+			Help:        "Time in seconds the Ingress status syncs wait in the queue",
+			Buckets:     prometheus.ExponentialBuckets(0.001, 4, 10),
+			ConstLabels: constLabels,
+		},
+	)).(prometheus.Histogram)
+
+	updates := registerOrReuse(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   collectors.PrometheusNamespace,
+			Name:        "ingress_status_updates_total",
+			Help:        "Number of Ingresses updated, skipped and failed by the Ingress status syncs",
+			ConstLabels: constLabels,
+		},
+		[]string{"result"},
+	)).(*prometheus.CounterVec)
+
+	lastSyncDuration := registerOrReuse(reg, prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace:   collectors.PrometheusNamespace,
+			Name:        "ingress_status_last_sync_duration_seconds",
+			Help:        "Duration in seconds of the last Ingress status sync",
+			ConstLabels: constLabels,
+		},
+	)).(prometheus.Gauge)
+
+	queueDepth := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace:   collectors.PrometheusNamespace,
+			Name:        "ingress_status_queue_depth",
+			Help:        "Number of Ingress status syncs waiting in the queue",
+			ConstLabels: constLabels,
+		},
+		func() float64 {
+			return float64(queue.Len())
+		},
+	)
+
+	err := reg.Register(queueDepth)
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		reg.Unregister(are.ExistingCollector)
+		err = reg.Register(queueDepth)
+	}
+	if err != nil {
+		klog.ErrorS(err, "registering Ingress status metrics")
+	}
+
+	return &statusMetrics{
+		queueLatency:     queueLatency,
+		updates:          updates,
+		lastSyncDuration: lastSyncDuration,
+	}
+}
+
+// registerOrReuse registers c in reg, returning the collector already
+// registered by another syncer if any
+func registerOrReuse(reg prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	err := reg.Register(c)
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		return are.ExistingCollector
+	}
+	if err != nil {
+		klog.ErrorS(err, "registering Ingress status metrics")
+	}
+
+	return c
+}
+
+// statusConstLabels returns the labels of the controller pod, the same
+// added to the controller metrics
+func statusConstLabels() prometheus.Labels {
+	namespace, pod := "", ""
+	if k8s.IngressPodDetails != nil {
+		namespace, pod = k8s.IngressPodDetails.Namespace, k8s.IngressPodDetails.Name
+	}
+
+	return prometheus.Labels{
+		"controller_namespace": namespace,
+		"controller_class":     class.IngressClass,
+		"controller_pod":       pod,
+	}
+}
+
+// observeQueueLatency records the time the element waited in the queue
+func (sm *statusMetrics) observeQueueLatency(key interface{}) {
+	if sm == nil {
+		return
+	}
+
+	item, ok := key.(task.Element)
+	if !ok || item.Enqueued == 0 {
+		return
+	}
+
+	sm.queueLatency.Observe(time.Since(time.Unix(0, item.Enqueued)).Seconds())
+}
+
+// observeSyncResult counts the Ingresses updated, skipped and failed in a sync
+func (sm *statusMetrics) observeSyncResult(result syncResult) {
+	if sm == nil {
+		return
+	}
+
+	sm.updates.WithLabelValues("updated").Add(float64(result.updated))
+	sm.updates.WithLabelValues("skipped").Add(float64(result.skipped))
+	sm.updates.WithLabelValues("failed").Add(float64(result.failed))
+}
+
+// setLastSyncDuration updates the gauge of the duration of the last sync
+func (sm *statusMetrics) setLastSyncDuration(d time.Duration) {
+	if sm == nil {
+		return
+	}
+
+	sm.lastSyncDuration.Set(d.Seconds())
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"k8s.io/ingress-nginx/internal/ingress/metric/collectors"
+	"k8s.io/ingress-nginx/internal/k8s"
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+func TestQueueMetrics(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	syncer, err := NewStatusSyncer(Config{
+		Client:            buildSimpleClientSet(),
+		PublishService:    apiv1.NamespaceDefault + "/" + "foo",
+		IngressLister:     buildIngressLister(),
+		MetricsRegisterer: reg,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	depthMetric := func(value string) string {
+		return `
+			# HELP nginx_ingress_controller_ingress_status_queue_depth Number of Ingress status syncs waiting in the queue
+			# TYPE nginx_ingress_controller_ingress_status_queue_depth gauge
+			nginx_ingress_controller_ingress_status_queue_depth{controller_class="nginx",controller_namespace="default",controller_pod="foo_base_pod"} ` + value + `
+		`
+	}
+
+	metrics := []string{"nginx_ingress_controller_ingress_status_queue_depth"}
+	if err := collectors.GatherAndCompare(nil, depthMetric("0"), metrics, reg); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	fk.syncQueue.EnqueueTask(task.GetDummyObject("first"))
+	fk.syncQueue.EnqueueTask(task.GetDummyObject("second"))
+
+	if err := collectors.GatherAndCompare(nil, depthMetric("2"), metrics, reg); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	fk.metrics.observeQueueLatency(task.Element{Enqueued: time.Now().Add(-time.Second).UnixNano()})
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "nginx_ingress_controller_ingress_status_queue_latency_seconds" {
+			continue
+		}
+
+		h := mf.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 1 || h.GetSampleSum() < 1 {
+			t.Errorf("expected one observation of at least one second but returned %v", h)
+		}
+		return
+	}
+
+	t.Errorf("expected the nginx_ingress_controller_ingress_status_queue_latency_seconds metric")
+}
+
+// updatesTotal returns the sum of the ingress status updates counters
+// with the result label
+func updatesTotal(t *testing.T, g prometheus.Gatherer, result string) float64 {
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	total := 0.0
+	for _, mf := range mfs {
+		if mf.GetName() != "nginx_ingress_controller_ingress_status_updates_total" {
+			continue
+		}
+
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "result" && label.GetValue() == result {
+					total += m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return total
+}
+
+func TestUpdatesMetric(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	globalUpdated := updatesTotal(t, prometheus.DefaultGatherer, "updated")
+
+	reg := prometheus.NewRegistry()
+	syncer, err := NewStatusSyncer(Config{
+		Client:               buildSimpleClientSet(),
+		PublishStatusAddress: "10.0.0.1",
+		IngressLister:        buildIngressLister(),
+		MetricsRegisterer:    reg,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	// foo_ingress_non_01 does not exist in the API server
+	if err := fk.sync(task.GetDummyObject("sync status")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]float64{"updated": 1, "skipped": 1, "failed": 0}
+	for result, value := range expected {
+		if total := updatesTotal(t, reg, result); total != value {
+			t.Errorf("%v: returned %v but expected %v", result, total, value)
+		}
+	}
+
+	// the counters are incremented in each sync
+	if err := fk.sync(task.GetDummyObject("sync status")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if total := updatesTotal(t, reg, "updated"); total != 2 {
+		t.Errorf("returned %v updated Ingresses but expected 2", total)
+	}
+
+	if total := updatesTotal(t, prometheus.DefaultGatherer, "updated"); total != globalUpdated {
+		t.Errorf("expected the global registry to be unchanged but returned %v (was %v)", total, globalUpdated)
+	}
+}
+
+func TestLastSyncDuration(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	reg := prometheus.NewRegistry()
+	syncer, err := NewStatusSyncer(Config{
+		Client:               buildSimpleClientSet(),
+		PublishStatusAddress: "10.0.0.1",
+		IngressLister:        buildIngressLister(),
+		MetricsRegisterer:    reg,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	// each update of an Ingress takes 3 seconds
+	fakeClock := clock.NewFakeClock(time.Now())
+	fk.clock = fakeClock
+	fk.OnStatusUpdated = func(string, []apiv1.LoadBalancerIngress) {
+		fakeClock.Step(3 * time.Second)
+	}
+
+	if err := fk.sync(task.GetDummyObject("sync status")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := fk.LastSyncDuration(); d != 3*time.Second {
+		t.Errorf("returned %v but expected %v", d, 3*time.Second)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value := -1.0
+	for _, mf := range mfs {
+		if mf.GetName() == "nginx_ingress_controller_ingress_status_last_sync_duration_seconds" {
+			value = mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	if value != 3 {
+		t.Errorf("returned %v but expected 3", value)
+	}
+
+	// only the duration of the last sync is reported
+	fk.OnStatusUpdated = func(string, []apiv1.LoadBalancerIngress) {
+		fakeClock.Step(time.Second)
+	}
+	if err := fk.sync(task.GetDummyObject("sync status")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := fk.LastSyncDuration(); d != time.Second {
+		t.Errorf("returned %v but expected %v", d, time.Second)
+	}
+}
+
+func TestRegisterOrReuse(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	opts := prometheus.GaugeOpts{Name: "foo", Help: "foo gauge"}
+
+	first := prometheus.NewGauge(opts)
+	if c := registerOrReuse(reg, first); c != first {
+		t.Errorf("expected the registered collector")
+	}
+
+	// the collector registered by another syncer is reused
+	if c := registerOrReuse(reg, prometheus.NewGauge(opts)); c != first {
+		t.Errorf("expected the collector already registered")
+	}
+}
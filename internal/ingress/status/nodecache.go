@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// nodeCache contains the nodes watched by an informer while this instance
+// is the leader, so the addresses of the nodes running the controller pods
+// are not read from the API server in every sync. The informer keeps the
+// cache up to date when the nodes are added, updated or deleted.
+type nodeCache struct {
+	lock sync.RWMutex
+
+	store  cache.Store
+	synced cache.InformerSynced
+}
+
+// set replaces the store of the cache. A nil store disables the cache.
+func (c *nodeCache) set(store cache.Store, synced cache.InformerSynced) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.store = store
+	c.synced = synced
+}
+
+// get returns the node from the cache. Returns false if the cache is
+// disabled, not synced yet or does not contain the node.
+func (c *nodeCache) get(name string) (*apiv1.Node, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.store == nil || !c.synced() {
+		return nil, false
+	}
+
+	obj, exists, err := c.store.GetByKey(name)
+	if err != nil || !exists {
+		return nil, false
+	}
+
+	node, ok := obj.(*apiv1.Node)
+	return node, ok
+}
+
+// getNode returns the node from the node cache, or from the API server if
+// the node is not cached. The returned node must not be modified.
+func (s *statusSync) getNode(name string) (*apiv1.Node, error) {
+	if node, ok := s.nodes.get(name); ok {
+		return node, nil
+	}
+
+	return s.Client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+}
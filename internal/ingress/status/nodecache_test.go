@@ -0,0 +1,330 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/ingress-nginx/internal/k8s"
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+// nodeGets returns the number of nodes read from the API server
+func nodeGets(client *testclient.Clientset) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "get" && action.GetResource().Resource == "nodes" {
+			count++
+		}
+	}
+
+	return count
+}
+
+// buildNodeCacheStatusSync returns a statusSync publishing the node addresses
+// of the pods of buildSimpleClientSet, caching the nodes if stopCh is not nil
+func buildNodeCacheStatusSync(t testing.TB, stopCh chan struct{}) (statusSync, *testclient.Clientset) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.nodes = &nodeCache{}
+
+	if stopCh != nil {
+		fk.watchNodes(stopCh)
+
+		err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			_, ok := fk.nodes.get("foo_node_2")
+			return ok, nil
+		})
+		if err != nil {
+			t.Fatalf("expected the node cache to be synced")
+		}
+	}
+
+	return fk, client
+}
+
+func TestRunningAddressesWithNodeCache(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	fk, client := buildNodeCacheStatusSync(t, stopCh)
+
+	// consistent with TestRunningAddressesWithPods
+	for i := 0; i < 3; i++ {
+		ra, err := fk.runningAddresses()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ra) != 1 || ra[0] != "11.0.0.2" {
+			t.Errorf("returned %v but expected %v", ra, []string{"11.0.0.2"})
+		}
+	}
+
+	if gets := nodeGets(client); gets != 0 {
+		t.Errorf("expected the nodes to be read from the cache but returned %v API calls", gets)
+	}
+
+	// the cache is updated when the node changes
+	node, err := client.CoreV1().Nodes().Get(context.TODO(), "foo_node_2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	node.Status.Addresses = []apiv1.NodeAddress{{Type: apiv1.NodeExternalIP, Address: "11.0.0.3"}}
+	if _, err := client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		ra, err := fk.runningAddresses()
+		return err == nil && len(ra) == 1 && ra[0] == "11.0.0.3", nil
+	})
+	if err != nil {
+		t.Errorf("expected the running addresses to contain the updated node address")
+	}
+
+	// the node is read from the API server once deleted from the cache
+	if err := client.CoreV1().Nodes().Delete(context.TODO(), "foo_node_2", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		ra, err := fk.runningAddresses()
+		return err == nil && len(ra) == 0, nil
+	})
+	if err != nil {
+		t.Errorf("expected no running addresses after the node is deleted")
+	}
+}
+
+func TestControllerNodeUpdated(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	fk, client := buildNodeCacheStatusSync(t, nil)
+	// the controller pod foo1 runs in the node foo_node_2
+	k8s.IngressPodDetails.Name = "foo1"
+
+	fk.syncQueue = task.NewTaskQueue(fk.sync)
+	go fk.syncQueue.Run(10*time.Millisecond, stopCh)
+	fk.watchNodes(stopCh)
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, ok := fk.nodes.get("foo_node_2")
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the node cache to be synced")
+	}
+
+	node, err := client.CoreV1().Nodes().Get(context.TODO(), "foo_node_2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	node.Status.Addresses = []apiv1.NodeAddress{{Type: apiv1.NodeExternalIP, Address: "11.0.0.3"}}
+	if _, err := client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the status is republished without waiting for the periodic sync
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		lbi := ing.Status.LoadBalancer.Ingress
+		return len(lbi) == 1 && lbi[0].IP == "11.0.0.3", nil
+	})
+	if err != nil {
+		t.Errorf("expected the Ingress status to contain the new node address: %v", err)
+	}
+}
+
+func TestNodeUpdatedIgnoredChanges(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo_node_2",
+		},
+		Status: apiv1.NodeStatus{
+			Addresses: []apiv1.NodeAddress{{Type: apiv1.NodeExternalIP, Address: "11.0.0.2"}},
+		},
+	}
+
+	// changes not modifying the published addresses
+	heartbeat := node.DeepCopy()
+	heartbeat.Status.Conditions = []apiv1.NodeCondition{{Type: apiv1.NodeReady, Status: apiv1.ConditionTrue}}
+	fk.nodeUpdated("foo_node_2", node, heartbeat)
+
+	// changes of the addresses of other nodes
+	other := node.DeepCopy()
+	other.Name = "foo_node_1"
+	otherUpdated := other.DeepCopy()
+	otherUpdated.Status.Addresses[0].Address = "10.0.0.9"
+	fk.nodeUpdated("foo_node_2", other, otherUpdated)
+
+	// the node of the controller pod is unknown
+	updated := node.DeepCopy()
+	updated.Status.Addresses[0].Address = "11.0.0.3"
+	fk.nodeUpdated("", node, updated)
+
+	if n := fk.syncQueue.Len(); n != 0 {
+		t.Errorf("expected no sync but returned %v enqueued elements", n)
+	}
+
+	fk.nodeUpdated("foo_node_2", node, updated)
+	if n := fk.syncQueue.Len(); n != 1 {
+		t.Errorf("expected one sync but returned %v enqueued elements", n)
+	}
+}
+
+func TestSelectControllerNodes(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo1",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	client := buildSimpleClientSet()
+
+	// a second controller pod running on the same node
+	_, err := client.CoreV1().Pods(apiv1.NamespaceDefault).Create(context.TODO(), &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo4",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "foo_node_2",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			Conditions: []apiv1.PodCondition{
+				{
+					Type:   apiv1.PodReady,
+					Status: apiv1.ConditionTrue,
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.SelectControllerNodes = true
+	fk.nodes = &nodeCache{}
+
+	client.ClearActions()
+	if _, err := fk.runningAddresses(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, action := range client.Actions() {
+		if get, ok := action.(k8stesting.GetAction); ok && action.GetResource().Resource == "nodes" {
+			names = append(names, get.GetName())
+		}
+	}
+	if len(names) != 1 || names[0] != "foo_node_2" {
+		t.Errorf("expected only the node foo_node_2 to be fetched but returned %v", names)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	client.ClearActions()
+	fk.watchNodes(stopCh)
+
+	expected := "metadata.name=foo_node_2"
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		for _, action := range client.Actions() {
+			list, ok := action.(k8stesting.ListAction)
+			if ok && action.GetResource().Resource == "nodes" {
+				if selector := list.GetListRestrictions().Fields.String(); selector != expected {
+					t.Errorf("returned field selector %q but expected %q", selector, expected)
+				}
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the nodes to be listed")
+	}
+}
+
+func BenchmarkRunningAddressesNodeGets(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		name := "api"
+		if cached {
+			name = "cache"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			var stopCh chan struct{}
+			if cached {
+				stopCh = make(chan struct{})
+				defer close(stopCh)
+			}
+
+			fk, client := buildNodeCacheStatusSync(b, stopCh)
+			client.ClearActions()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := fk.runningAddresses(); err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(nodeGets(client))/float64(b.N), "node-gets/op")
+		})
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// podPublishAddressAnnotation is the annotation, without prefix, of a
+// controller pod containing the addresses to publish instead of the ones
+// of its node, like the NAT-mapped address of a host network pod
+const podPublishAddressAnnotation = "publish-address"
+
+// podPublishAddresses returns the valid addresses of the publish-address
+// annotation of the pod. Returns false if the pod has no such addresses.
+func podPublishAddresses(pod *apiv1.Pod) ([]string, bool) {
+	value := strings.TrimSpace(pod.Annotations[parser.GetAnnotationWithPrefix(podPublishAddressAnnotation)])
+	if value == "" {
+		return nil, false
+	}
+
+	addrs := []string{}
+	for _, addr := range splitAddresses(value) {
+		if !isValidAddress(addr) {
+			klog.Warningf("ignoring invalid address %q in the %v annotation of pod %v/%v", addr, podPublishAddressAnnotation, pod.Namespace, pod.Name)
+			continue
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, len(addrs) > 0
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// controllerPod returns a Ready controller pod running in the node with
+// the given publish-address annotation
+func controllerPod(name, node, publishAddress string) *apiv1.Pod {
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: node,
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			Conditions: []apiv1.PodCondition{
+				{
+					Type:   apiv1.PodReady,
+					Status: apiv1.ConditionTrue,
+				},
+			},
+		},
+	}
+
+	if publishAddress != "" {
+		pod.Annotations = map[string]string{
+			parser.GetAnnotationWithPrefix(podPublishAddressAnnotation): publishAddress,
+		}
+	}
+
+	return pod
+}
+
+func TestRunningAddressesWithPodPublishAddress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pods     []*apiv1.Pod
+		expected []string
+	}{
+		{
+			"annotation preferred over the node address",
+			[]*apiv1.Pod{controllerPod("foo1", "foo_node_2", "203.0.113.10")},
+			[]string{"203.0.113.10"},
+		},
+		{
+			"several addresses",
+			[]*apiv1.Pod{controllerPod("foo1", "foo_node_2", "203.0.113.10, lb.example.com")},
+			[]string{"203.0.113.10", "lb.example.com"},
+		},
+		{
+			"pods without the annotation use the node address",
+			[]*apiv1.Pod{
+				controllerPod("foo1", "foo_node_2", ""),
+				controllerPod("foo5", "foo_node_1", "203.0.113.11"),
+			},
+			[]string{"11.0.0.2", "203.0.113.11"},
+		},
+		{
+			"invalid annotation",
+			[]*apiv1.Pod{controllerPod("foo1", "foo_node_2", "not_an_address")},
+			[]string{"11.0.0.2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k8s.IngressPodDetails = &k8s.PodInfo{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo1",
+					Namespace: apiv1.NamespaceDefault,
+					Labels: map[string]string{
+						"label_sig": "foo_pod",
+					},
+				},
+			}
+
+			fk := buildStatusSync()
+			fk.PublishService = ""
+
+			pods := fk.Client.CoreV1().Pods(apiv1.NamespaceDefault)
+			for _, pod := range tc.pods {
+				var err error
+				if pod.Name == "foo1" {
+					_, err = pods.Update(context.TODO(), pod, metav1.UpdateOptions{})
+				} else {
+					_, err = pods.Create(context.TODO(), pod, metav1.CreateOptions{})
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			ra, err := fk.runningAddresses()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ra, tc.expected) {
+				t.Errorf("returned %v but expected %v", ra, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// statusPortsAnnotation is the annotation, without prefix, containing the
+// ports of the publish service, as the status of the Ingress has no ports
+const statusPortsAnnotation = "status-ports"
+
+// servicePorts returns the ports of the service as a comma separated list
+// of port/protocol, in the order of the service spec
+func servicePorts(svc *apiv1.Service) string {
+	ports := make([]string, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = apiv1.ProtocolTCP
+		}
+
+		ports = append(ports, fmt.Sprintf("%v/%v", port.Port, protocol))
+	}
+
+	return strings.Join(ports, ",")
+}
+
+// publishServicePorts returns the ports of the publish service to write in
+// the status-ports annotation. Returns false if PublishServicePorts is not
+// set, there is no publish service or the service cannot be read.
+func (s *statusSync) publishServicePorts() (string, bool) {
+	if !s.PublishServicePorts || s.PublishStatusAddress != "" {
+		return "", false
+	}
+
+	svc, ok, err := s.getPublishService()
+	if !ok {
+		return "", false
+	}
+
+	if err != nil {
+		klog.Warningf("error obtaining the ports of the publish service: %v", err)
+		return "", false
+	}
+
+	return servicePorts(svc), true
+}
+
+// getPublishService returns the publish service, set with PublishService or
+// PublishServiceSelector. Returns false if there is no publish service.
+func (s *statusSync) getPublishService() (*apiv1.Service, bool, error) {
+	switch {
+	case s.PublishService != "":
+		ns, name, _ := k8s.ParseNameNS(s.PublishService)
+		svc, err := s.Client.CoreV1().Services(ns).Get(context.TODO(), name, metav1.GetOptions{})
+		return svc, true, err
+	case s.PublishServiceSelector != nil:
+		svc, err := s.publishServiceBySelector()
+		return svc, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// statusPortsAnnotations returns the status-ports annotation to write in the
+// Ingress, or nil if the annotation is up to date. Without ports, the
+// annotation is returned to be removed.
+func statusPortsAnnotations(ing *networking.Ingress, ports string) (map[string]string, []string) {
+	key := parser.GetAnnotationWithPrefix(statusPortsAnnotation)
+	if ports == "" {
+		return nil, staleAnnotation(ing, key)
+	}
+
+	if value, ok := ing.Annotations[key]; ok && value == ports {
+		return nil, nil
+	}
+
+	return map[string]string{key: ports}, nil
+}
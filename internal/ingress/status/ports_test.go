@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// buildServiceWithPorts returns a load balancer service exposing HTTP and HTTPS
+func buildServiceWithPorts() *apiv1.Service {
+	return &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ports",
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Spec: apiv1.ServiceSpec{
+			Type: apiv1.ServiceTypeLoadBalancer,
+			Ports: []apiv1.ServicePort{
+				{Name: "http", Port: 80, Protocol: apiv1.ProtocolTCP},
+				{Name: "https", Port: 443},
+			},
+		},
+		Status: apiv1.ServiceStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+			},
+		},
+	}
+}
+
+func TestPublishServicePorts(t *testing.T) {
+	client := testclient.NewSimpleClientset(buildServiceWithPorts(), &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = "default/foo_ports"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishServicePorts = true
+
+	key := parser.GetAnnotationWithPrefix(statusPortsAnnotation)
+	assertPorts := func(expected string) {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ing.Annotations[key] != expected {
+			t.Errorf("returned %v but expected %v", ing.Annotations[key], expected)
+		}
+	}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+	assertPorts("80/TCP,443/TCP")
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.skipped != 1 {
+		t.Errorf("expected one skipped Ingress but returned %+v", r)
+	}
+
+	// the annotation is updated when only the ports change
+	svc := buildServiceWithPorts()
+	svc.Spec.Ports = append(svc.Spec.Ports, apiv1.ServicePort{Name: "dns", Port: 53, Protocol: apiv1.ProtocolUDP})
+	if _, err := client.CoreV1().Services(apiv1.NamespaceDefault).Update(context.TODO(), svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+	assertPorts("80/TCP,443/TCP,53/UDP")
+
+	// the annotation is removed when the service has no ports
+	svc.Spec.Ports = nil
+	if _, err := client.CoreV1().Services(apiv1.NamespaceDefault).Update(context.TODO(), svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ing.Annotations[key]; ok {
+		t.Errorf("expected the annotation to be removed but returned %v", ing.Annotations)
+	}
+}
+
+func TestPublishServicePortsDisabled(t *testing.T) {
+	client := testclient.NewSimpleClientset(buildServiceWithPorts(), &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = "default/foo_ports"
+	fk.IngressLister = &clientIngressLister{client}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, ok := ing.Annotations[parser.GetAnnotationWithPrefix(statusPortsAnnotation)]; ok {
+		t.Errorf("unexpected status ports annotation %v", value)
+	}
+	if len(ing.Status.LoadBalancer.Ingress) != 1 || ing.Status.LoadBalancer.Ingress[0].Hostname != "lb.example.com" {
+		t.Errorf("unexpected status %v", ing.Status.LoadBalancer.Ingress)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"sync/atomic"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// PrepareShutdown marks the controller pod as terminating, so the address
+// of its node is no longer published, and updates the status right away
+// instead of waiting for the pod to be removed from the ready pods. The
+// status is only updated by the leader, when the addresses of the nodes
+// are published and other controller pods are Ready. Without other Ready
+// pods the status is cleared by Shutdown.
+func (s *statusSync) PrepareShutdown() error {
+	atomic.StoreInt32(&s.terminating, 1)
+
+	if !s.UpdateStatusOnShutdown || !s.usesNodeAddresses() {
+		return nil
+	}
+
+	if atomic.LoadInt32(&s.leading) == 0 {
+		klog.V(2).InfoS("skipping removal of the terminating pod from the Ingress status (not the leader)")
+		return nil
+	}
+
+	ready, err := s.otherReadyPods()
+	if err != nil {
+		klog.ErrorS(err, "error listing the ingress controller pods, skipping the removal of the terminating pod from the Ingress status")
+		return nil
+	}
+	if ready == 0 {
+		return nil
+	}
+
+	// wait for the syncs started before the pod was marked as terminating
+	s.syncLock.Lock()
+	defer s.syncLock.Unlock()
+
+	klog.InfoS("removing the node of the terminating controller pod from the Ingress status")
+	_, err = s.reconcile(context.Background())
+	return err
+}
+
+// isTerminatingPod returns true if the pod is this controller pod and it is
+// terminating
+func (s *statusSync) isTerminatingPod(pod *apiv1.Pod) bool {
+	if atomic.LoadInt32(&s.terminating) == 0 || k8s.IngressPodDetails == nil {
+		return false
+	}
+
+	return pod.Namespace == k8s.IngressPodDetails.Namespace && pod.Name == k8s.IngressPodDetails.Name
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// buildPrepareShutdownStatusSync returns a syncer of the controller pod foo1,
+// running in foo_node_2, with a second controller pod running in foo_node_1
+func buildPrepareShutdownStatusSync(t *testing.T) (statusSync, *testclient.Clientset) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo1",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	client := buildSimpleClientSet()
+	_, err := client.CoreV1().Pods(apiv1.NamespaceDefault).Create(context.TODO(), &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo5",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "foo_node_1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			Conditions: []apiv1.PodCondition{
+				{
+					Type:   apiv1.PodReady,
+					Status: apiv1.ConditionTrue,
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.IngressLister = &clientIngressLister{client}
+	fk.UpdateStatusOnShutdown = true
+
+	return fk, client
+}
+
+func getIngressStatus(t *testing.T, client *testclient.Clientset, name string) []apiv1.LoadBalancerIngress {
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return ing.Status.LoadBalancer.Ingress
+}
+
+func TestPrepareShutdown(t *testing.T) {
+	fk, client := buildPrepareShutdownStatusSync(t)
+	fk.setLeading(true)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}, {IP: "11.0.0.2"}}
+	if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, expected) {
+		t.Fatalf("returned %v but expected %v", status, expected)
+	}
+
+	// SIGTERM: the node of the terminating pod is removed before the shutdown
+	if err := fk.PrepareShutdown(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+
+	// the following syncs keep excluding the terminating pod
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fk.Shutdown(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestPrepareShutdownNotLeader(t *testing.T) {
+	fk, client := buildPrepareShutdownStatusSync(t)
+
+	if err := fk.PrepareShutdown(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the status is left to the leader
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1", Hostname: "foo1"}}
+	if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestShutdownWithReadyReplica(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ready    apiv1.ConditionStatus
+		expected []apiv1.LoadBalancerIngress
+	}{
+		// rolling update: the new replica keeps publishing the address
+		{"ready replica", apiv1.ConditionTrue, []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}},
+		{"replica not ready", apiv1.ConditionFalse, []apiv1.LoadBalancerIngress{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fk, client := buildPrepareShutdownStatusSync(t)
+			fk.PublishStatusAddress = "10.0.0.1"
+			fk.setLeading(true)
+
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pods := client.CoreV1().Pods(apiv1.NamespaceDefault)
+			replica, err := pods.Get(context.TODO(), "foo5", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			replica.Status.Conditions[0].Status = tc.ready
+			if _, err := pods.UpdateStatus(context.TODO(), replica, metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := fk.Shutdown(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, tc.expected) {
+				t.Errorf("returned %v but expected %v", status, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPrepareShutdownWaitsForSync(t *testing.T) {
+	fk, client := buildPrepareShutdownStatusSync(t)
+	fk.setLeading(true)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a sync started before the pod was marked as terminating
+	fk.syncLock.RLock()
+
+	done := make(chan error)
+	go func() {
+		done <- fk.PrepareShutdown()
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("expected PrepareShutdown to wait for the running sync")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	fk.syncLock.RUnlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestPrepareShutdownWithoutReadyReplica(t *testing.T) {
+	fk, client := buildPrepareShutdownStatusSync(t)
+	fk.setLeading(true)
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pods := client.CoreV1().Pods(apiv1.NamespaceDefault)
+	replica, err := pods.Get(context.TODO(), "foo5", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replica.Status.Conditions[0].Status = apiv1.ConditionFalse
+	if _, err := pods.UpdateStatus(context.TODO(), replica, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fk.PrepareShutdown(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the status is left to Shutdown
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}, {IP: "11.0.0.2"}}
+	if status := getIngressStatus(t, client, "foo_ingress_1"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
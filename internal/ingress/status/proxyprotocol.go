@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// proxyProtocolAnnotation is the annotation, without prefix, marking the
+// Ingresses whose published addresses are fronted by PROXY protocol
+const proxyProtocolAnnotation = "proxy-protocol"
+
+// proxyProtocolAnnotations returns the proxy-protocol annotation of an
+// Ingress with published addresses, or nil if it is already set. When
+// disabled or without addresses, the annotation is returned to be removed.
+func proxyProtocolAnnotations(ing *networking.Ingress, addrs []apiv1.LoadBalancerIngress, enabled bool) (map[string]string, []string) {
+	key := parser.GetAnnotationWithPrefix(proxyProtocolAnnotation)
+	if !enabled || len(addrs) == 0 {
+		return nil, staleAnnotation(ing, key)
+	}
+
+	if ing.Annotations[key] == "true" {
+		return nil, nil
+	}
+
+	return map[string]string{key: "true"}, nil
+}
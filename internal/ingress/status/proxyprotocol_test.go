@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+func TestProxyProtocolAnnotation(t *testing.T) {
+	key := parser.GetAnnotationWithPrefix(proxyProtocolAnnotation)
+
+	testCases := map[string]struct {
+		enabled  bool
+		expected string
+	}{
+		"enabled":  {enabled: true, expected: "true"},
+		"disabled": {enabled: false, expected: ""},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			client := testclient.NewSimpleClientset(&networking.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo_ingress",
+					Namespace: apiv1.NamespaceDefault,
+				},
+			})
+
+			fk := buildStatusSync()
+			fk.Client = client
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "10.0.0.1"
+			fk.IngressLister = &clientIngressLister{client}
+			fk.PublishProxyProtocol = tc.enabled
+
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ing.Annotations[key] != tc.expected {
+				t.Errorf("returned %q but expected %q", ing.Annotations[key], tc.expected)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolAnnotations(t *testing.T) {
+	key := parser.GetAnnotationWithPrefix(proxyProtocolAnnotation)
+	addrs := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+
+	ing := &networking.Ingress{}
+	if annotations, _ := proxyProtocolAnnotations(ing, addrs, true); annotations[key] != "true" {
+		t.Errorf("expected the annotation but returned %v", annotations)
+	}
+
+	// the annotation is not written without addresses or when it is set
+	if annotations, removed := proxyProtocolAnnotations(ing, nil, true); annotations != nil || removed != nil {
+		t.Errorf("expected no annotation without addresses but returned %v, %v", annotations, removed)
+	}
+
+	ing.Annotations = map[string]string{key: "true"}
+	if annotations, removed := proxyProtocolAnnotations(ing, addrs, true); annotations != nil || removed != nil {
+		t.Errorf("expected no annotation when it is set but returned %v, %v", annotations, removed)
+	}
+
+	// the annotation is removed when disabled or without addresses
+	for _, tc := range []struct {
+		addrs   []apiv1.LoadBalancerIngress
+		enabled bool
+	}{{addrs, false}, {nil, true}} {
+		annotations, removed := proxyProtocolAnnotations(ing, tc.addrs, tc.enabled)
+		if annotations != nil || !reflect.DeepEqual(removed, []string{key}) {
+			t.Errorf("expected the removal of the annotation but returned %v, %v", annotations, removed)
+		}
+	}
+}
+
+func TestProxyProtocolAnnotationRemoved(t *testing.T) {
+	key := parser.GetAnnotationWithPrefix(proxyProtocolAnnotation)
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishProxyProtocol = true
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ing.Annotations[key] != "true" {
+		t.Fatalf("expected the annotation but returned %v", ing.Annotations)
+	}
+
+	// the annotation is removed after the option is disabled
+	fk.PublishProxyProtocol = false
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ing.Annotations[key]; ok {
+		t.Errorf("expected the annotation to be removed but returned %v", ing.Annotations)
+	}
+}
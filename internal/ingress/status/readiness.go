@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	networking "k8s.io/api/networking/v1beta1"
+)
+
+// ingressReadiness returns if the Ingress is marked as ready by the
+// RequireReadyAnnotation, and if the annotation is present. All the
+// Ingresses are ready when RequireReadyAnnotation is empty.
+func (s *statusSync) ingressReadiness(ing *networking.Ingress) (ready bool, found bool) {
+	if s.RequireReadyAnnotation == "" {
+		return true, true
+	}
+
+	value, found := ing.Annotations[s.RequireReadyAnnotation]
+	if !found {
+		return false, false
+	}
+
+	return value == "true", true
+}
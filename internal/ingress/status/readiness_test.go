@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+const testReadyAnnotation = "example.com/ready"
+
+// buildReadinessIngress returns an Ingress published in 10.0.0.9 with the
+// given annotations
+func buildReadinessIngress(name string, annotations map[string]string) *networking.Ingress {
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   apiv1.NamespaceDefault,
+			Annotations: annotations,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.9"}},
+			},
+		},
+	}
+}
+
+func TestRequireReadyAnnotation(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		buildReadinessIngress("foo_missing", nil),
+		buildReadinessIngress("foo_ready", map[string]string{testReadyAnnotation: "true"}),
+		buildReadinessIngress("foo_not_ready", map[string]string{testReadyAnnotation: "false"}),
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.RequireReadyAnnotation = testReadyAnnotation
+
+	assertStatus := func(name string, expected []apiv1.LoadBalancerIngress) {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, expected) {
+			t.Errorf("%v: returned %v but expected %v", name, ing.Status.LoadBalancer.Ingress, expected)
+		}
+	}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 2 || r.skipped != 1 {
+		t.Errorf("expected two updated and one skipped Ingresses but returned %+v", r)
+	}
+
+	assertStatus("foo_missing", []apiv1.LoadBalancerIngress{{IP: "10.0.0.9"}})
+	assertStatus("foo_ready", []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+	assertStatus("foo_not_ready", []apiv1.LoadBalancerIngress{})
+
+	// the status is cleared when the Ingress is no longer ready
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ready", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ing.Annotations[testReadyAnnotation] = "false"
+	if _, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Update(context.TODO(), ing, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	diffs, err := fk.SyncDiff(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].Name != "foo_ready" || len(diffs[0].Desired) != 0 {
+		t.Errorf("expected an empty desired status of foo_ready but returned %+v", diffs)
+	}
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 || r.skipped != 2 {
+		t.Errorf("expected one updated and two skipped Ingresses but returned %+v", r)
+	}
+
+	assertStatus("foo_ready", []apiv1.LoadBalancerIngress{})
+	assertStatus("foo_missing", []apiv1.LoadBalancerIngress{{IP: "10.0.0.9"}})
+}
+
+func TestRequireReadyAnnotationDisabled(t *testing.T) {
+	fk := buildStatusSync()
+
+	ing := buildReadinessIngress("foo_missing", nil)
+	if ready, found := fk.ingressReadiness(ing); !ready || !found {
+		t.Errorf("expected the Ingress to be ready but returned ready=%v found=%v", ready, found)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// DefaultStatusReportName is the suggested name of the ConfigMap
+// containing the report of the status updates
+const DefaultStatusReportName = "ingress-status-report"
+
+// keys of the status report ConfigMap
+const (
+	reportAddressesKey       = "addresses"
+	reportLastSyncKey        = "last-sync"
+	reportUpdatedKey         = "updated"
+	reportSkippedKey         = "skipped"
+	reportFailedKey          = "failed"
+	reportFailedIngressesKey = "failed-ingresses"
+)
+
+// writeReport saves the result of the last reconciliation of the status
+// in the ConfigMap configured in StatusReportConfigMap
+func (s *statusSync) writeReport(addrs []apiv1.LoadBalancerIngress, result syncResult) {
+	if s.StatusReportConfigMap == "" {
+		return
+	}
+
+	ns, name, err := k8s.ParseNameNS(s.StatusReportConfigMap)
+	if err != nil {
+		klog.Warningf("invalid status report ConfigMap %v: %v", s.StatusReportConfigMap, err)
+		return
+	}
+
+	failed := make([]string, 0)
+	for key := range s.updateErrors.list() {
+		failed = append(failed, key)
+	}
+	sort.Strings(failed)
+
+	data := map[string]string{
+		reportAddressesKey:       annotationFromStatus(addrs),
+		reportLastSyncKey:        time.Now().UTC().Format(time.RFC3339),
+		reportUpdatedKey:         strconv.Itoa(result.updated),
+		reportSkippedKey:         strconv.Itoa(result.skipped),
+		reportFailedKey:          strconv.Itoa(result.failed),
+		reportFailedIngressesKey: strings.Join(failed, ","),
+	}
+
+	cmClient := s.Client.CoreV1().ConfigMaps(ns)
+	cm, err := cmClient.Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = cmClient.Create(context.TODO(), &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+			Data: data,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Warningf("error creating status report ConfigMap %v: %v", s.StatusReportConfigMap, err)
+		}
+
+		return
+	}
+	if err != nil {
+		klog.Warningf("error getting status report ConfigMap %v: %v", s.StatusReportConfigMap, err)
+		return
+	}
+
+	cm.Data = data
+	_, err = cmClient.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Warningf("error updating status report ConfigMap %v: %v", s.StatusReportConfigMap, err)
+	}
+}
+
+// logSyncSummary logs the result of a sync and the last published
+// addresses, at the SyncSummaryLogLevel
+func (s *statusSync) logSyncSummary(result syncResult) {
+	klog.V(s.SyncSummaryLogLevel).InfoS("synced Ingress status",
+		"examined", result.updated+result.skipped+result.failed,
+		"updated", result.updated,
+		"skipped", result.skipped,
+		"failed", result.failed,
+		"addresses", annotationFromStatus(s.lastStatus.get()))
+}
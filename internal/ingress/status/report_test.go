@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+func TestStatusReport(t *testing.T) {
+	writer := &failingStatusWriter{
+		fail: map[string]bool{"default/foo_ingress_1": true},
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "foo.bar.com,10.0.0.1"
+	fk.StatusWriter = writer
+	fk.StatusReportConfigMap = apiv1.NamespaceDefault + "/" + DefaultStatusReportName
+
+	checkReport := func(expected map[string]string) {
+		cm, err := fk.Client.CoreV1().ConfigMaps(apiv1.NamespaceDefault).Get(context.TODO(), DefaultStatusReportName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for k, v := range expected {
+			if cm.Data[k] != v {
+				t.Errorf("%v: returned %v but expected %v", k, cm.Data[k], v)
+			}
+		}
+
+		if _, err := time.Parse(time.RFC3339, cm.Data[reportLastSyncKey]); err != nil {
+			t.Errorf("unexpected last sync time %v: %v", cm.Data[reportLastSyncKey], err)
+		}
+	}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checkReport(map[string]string{
+		reportAddressesKey:       "10.0.0.1,foo.bar.com",
+		reportUpdatedKey:         "1",
+		reportSkippedKey:         "0",
+		reportFailedKey:          "1",
+		reportFailedIngressesKey: "default/foo_ingress_1",
+	})
+
+	writer.fail = map[string]bool{}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checkReport(map[string]string{
+		reportAddressesKey:       "10.0.0.1,foo.bar.com",
+		reportUpdatedKey:         "2",
+		reportSkippedKey:         "0",
+		reportFailedKey:          "0",
+		reportFailedIngressesKey: "",
+	})
+}
+
+func TestStatusReportDisabled(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := fk.Client.CoreV1().ConfigMaps(apiv1.NamespaceDefault).Get(context.TODO(), DefaultStatusReportName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected no status report but returned %v", err)
+	}
+}
+
+func TestSyncSummaryLog(t *testing.T) {
+	var buf logBuffer
+	klog.LogToStderr(false)
+	klog.SetOutput(&buf)
+	defer func() {
+		klog.SetOutput(os.Stderr)
+		klog.LogToStderr(true)
+	}()
+
+	writer := &failingStatusWriter{
+		fail: map[string]bool{"default/foo_ingress_1": true},
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "foo.bar.com,10.0.0.1"
+	fk.StatusWriter = writer
+
+	// the failed update is retried, but the summary is logged
+	if err := fk.sync(task.GetDummyObject("sync status")); err == nil {
+		t.Fatalf("expected an error for the failed update")
+	}
+	klog.Flush()
+
+	expected := `"synced Ingress status" examined=2 updated=1 skipped=0 failed=1 addresses="10.0.0.1,foo.bar.com"`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the log to contain %v but returned %v", expected, buf.String())
+	}
+
+	if n := strings.Count(buf.String(), "synced Ingress status"); n != 1 {
+		t.Errorf("expected one summary per sync but returned %v", n)
+	}
+
+	// the summary is not logged above the klog verbosity
+	fk.SyncSummaryLogLevel = 10
+	writer.fail = map[string]bool{}
+
+	if err := fk.sync(task.GetDummyObject("sync status")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	klog.Flush()
+
+	if n := strings.Count(buf.String(), "synced Ingress status"); n != 1 {
+		t.Errorf("expected no summary above the klog verbosity but returned %v", n)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultMetadataTimeout is the timeout of the requests to the cloud
+	// metadata endpoint when CloudMetadataResolver.Timeout is not set
+	defaultMetadataTimeout = 2 * time.Second
+
+	// maxMetadataResponseSize is the max number of bytes read from the
+	// cloud metadata endpoint
+	maxMetadataResponseSize = 1024
+)
+
+// AddressResolver returns additional addresses to publish in the Ingress status
+type AddressResolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// CloudMetadataResolver returns the external IP of the instance from the
+// metadata endpoint of the cloud provider. The IP is cached after the
+// first successful request.
+type CloudMetadataResolver struct {
+	// URL is the metadata endpoint returning the external IP as plain text
+	URL string
+	// Timeout is the max duration of the request to the metadata endpoint
+	Timeout time.Duration
+	// Header contains additional headers sent to the metadata endpoint,
+	// like Metadata-Flavor: Google
+	Header http.Header
+
+	client *http.Client
+
+	lock sync.Mutex
+	ip   string
+}
+
+// NewCloudMetadataResolver returns a resolver reading the external IP from url
+func NewCloudMetadataResolver(url string, timeout time.Duration) *CloudMetadataResolver {
+	return &CloudMetadataResolver{
+		URL:     url,
+		Timeout: timeout,
+	}
+}
+
+// Resolve returns the external IP of the instance
+func (r *CloudMetadataResolver) Resolve(ctx context.Context) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.ip != "" {
+		return []string{r.ip}, nil
+	}
+
+	ip, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.ip = ip
+	return []string{ip}, nil
+}
+
+func (r *CloudMetadataResolver) fetch(ctx context.Context) (string, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = defaultMetadataTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	for name, values := range r.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %v from metadata endpoint %v", resp.StatusCode, r.URL)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMetadataResponseSize))
+	if err != nil {
+		return "", err
+	}
+
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("invalid IP address %q returned by metadata endpoint %v", ip, r.URL)
+	}
+
+	return ip, nil
+}
+
+// EnvAddressResolver returns the IP address contained in an environment
+// variable, like the node IP exposed with the downward API
+type EnvAddressResolver struct {
+	// Name is the name of the environment variable
+	Name string
+}
+
+// NewEnvAddressResolver returns a resolver reading the IP address from the
+// environment variable name
+func NewEnvAddressResolver(name string) *EnvAddressResolver {
+	return &EnvAddressResolver{
+		Name: name,
+	}
+}
+
+// Resolve returns the IP address contained in the environment variable
+func (r *EnvAddressResolver) Resolve(ctx context.Context) ([]string, error) {
+	ip := strings.TrimSpace(os.Getenv(r.Name))
+	if ip == "" {
+		return nil, fmt.Errorf("environment variable %v is not set", r.Name)
+	}
+
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("invalid IP address %q in environment variable %v", ip, r.Name)
+	}
+
+	return []string{ip}, nil
+}
+
+// resolvedAddresses adds the addresses returned by the configured
+// resolvers. Resolvers returning an error are skipped.
+func (s *statusSync) resolvedAddresses(ctx context.Context, addrs []string) []string {
+	for _, resolver := range s.AddressResolvers {
+		resolved, err := resolver.Resolve(ctx)
+		if err != nil {
+			klog.Warningf("error resolving addresses: %v", err)
+			continue
+		}
+
+		for _, addr := range resolved {
+			if !stringInSlice(addr, addrs) {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	return addrs
+}
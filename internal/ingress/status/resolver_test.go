@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/status/fake"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+func TestCloudMetadataResolver(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("203.0.113.7\n"))
+	}))
+	defer server.Close()
+
+	resolver := NewCloudMetadataResolver(server.URL, time.Second)
+	resolver.Header = http.Header{"Metadata-Flavor": []string{"Google"}}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.AddressResolvers = []AddressResolver{resolver}
+
+	for i := 0; i < 2; i++ {
+		if _, err := fk.reconcile(context.TODO()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "203.0.113.7"}}
+	if status := fk.lastStatus.get(); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected the IP to be cached after one request but got %v requests", n)
+	}
+}
+
+func TestCloudMetadataResolverErrors(t *testing.T) {
+	testCases := map[string]http.HandlerFunc{
+		"status code": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		},
+		"invalid IP": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("not-an-ip"))
+		},
+		"timeout": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+			w.Write([]byte("203.0.113.7"))
+		},
+	}
+
+	for name, handler := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			resolver := NewCloudMetadataResolver(server.URL, 100*time.Millisecond)
+			if addrs, err := resolver.Resolve(context.TODO()); err == nil {
+				t.Errorf("expected an error but returned %v", addrs)
+			}
+
+			// failed addresses are not published
+			fk := buildStatusSync()
+			fk.AddressResolvers = []AddressResolver{resolver}
+			addrs := fk.resolvedAddresses(context.TODO(), []string{"10.0.0.1"})
+			if !reflect.DeepEqual(addrs, []string{"10.0.0.1"}) {
+				t.Errorf("returned %v but expected %v", addrs, []string{"10.0.0.1"})
+			}
+		})
+	}
+}
+
+var _ AddressResolver = &fake.FakeResolver{}
+
+func TestUpdateStatusWithFakeResolver(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels:    map[string]string{"app": "ingress-nginx"},
+		},
+	}
+
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	resolver := fake.NewFakeResolver("lb.example.com", "10.0.0.1")
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.IngressLister = &clientIngressLister{client}
+	fk.AddressResolvers = []AddressResolver{resolver}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := sliceToStatus([]string{"10.0.0.1", "lb.example.com"})
+	if !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	// the addresses of a failing resolver are not published
+	resolver.SetError(fmt.Errorf("unexpected error"))
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ing.Status.LoadBalancer.Ingress) != 0 {
+		t.Errorf("expected an empty status but returned %v", ing.Status.LoadBalancer.Ingress)
+	}
+	if resolver.Calls() != 2 {
+		t.Errorf("expected two calls to the resolver but returned %v", resolver.Calls())
+	}
+}
+
+func TestNodeIPEnvVar(t *testing.T) {
+	const name = "TEST_STATUS_NODE_IP"
+
+	os.Setenv(name, "192.0.2.1")
+	defer os.Unsetenv(name)
+
+	client := buildSimpleClientSet()
+	resolvers := []AddressResolver{fake.NewFakeResolver("10.0.0.2")}
+
+	syncer, err := NewStatusSyncer(Config{
+		Client:               client,
+		PublishStatusAddress: "10.0.0.1",
+		IngressLister:        &clientIngressLister{client},
+		MetricsRegisterer:    prometheus.NewRegistry(),
+		AddressResolvers:     resolvers,
+		NodeIPEnvVar:         name,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	if len(resolvers) != 1 {
+		t.Errorf("expected the resolvers of the configuration to be unmodified but returned %v", resolvers)
+	}
+
+	addrs, err := fk.publishedAddresses(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"10.0.0.1", "10.0.0.2", "192.0.2.1"}
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("returned %v but expected %v", addrs, expected)
+	}
+
+	// invalid addresses are not published
+	os.Setenv(name, "node-1")
+	addrs, err = fk.publishedAddresses(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []string{"10.0.0.1", "10.0.0.2"}
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("returned %v but expected %v", addrs, expected)
+	}
+}
+
+func TestEnvAddressResolverErrors(t *testing.T) {
+	const name = "TEST_STATUS_NODE_IP"
+
+	defer os.Unsetenv(name)
+
+	for _, value := range []string{"", " ", "node-1", "10.0.0.256"} {
+		os.Setenv(name, value)
+
+		if addrs, err := NewEnvAddressResolver(name).Resolve(context.TODO()); err == nil {
+			t.Errorf("%q: expected an error but returned %v", value, addrs)
+		}
+	}
+}
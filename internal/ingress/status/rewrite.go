@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"regexp"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// HostnameRewrite replaces the matches of a regular expression in the
+// hostnames published in the status
+type HostnameRewrite struct {
+	// Pattern is the regular expression matched against the hostname
+	Pattern string
+	// Replacement replaces the matches of Pattern. It can reference the
+	// groups of the expression like $1
+	Replacement string
+}
+
+// hostnameRewrite is a HostnameRewrite with the compiled pattern
+type hostnameRewrite struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// parseHostnameRewrites compiles the patterns of the rules, returning an
+// error for the first invalid one
+func parseHostnameRewrites(rules []HostnameRewrite) ([]hostnameRewrite, error) {
+	result := make([]hostnameRewrite, 0, len(rules))
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hostname rewrite pattern %q: %v", rule.Pattern, err)
+		}
+
+		result = append(result, hostnameRewrite{re: re, replacement: rule.Replacement})
+	}
+
+	return result, nil
+}
+
+// rewriteHostnames applies the hostname rewrite rules, in order, to the
+// hostnames of the addresses. IP addresses are not modified. Hostnames
+// rewritten to an empty string are removed.
+func (s *statusSync) rewriteHostnames(addrs []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	if len(s.hostnameRewrites) == 0 {
+		return addrs
+	}
+
+	result := make([]apiv1.LoadBalancerIngress, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr.IP == "" {
+			hostname := addr.Hostname
+			for _, rule := range s.hostnameRewrites {
+				addr.Hostname = rule.re.ReplaceAllString(addr.Hostname, rule.replacement)
+			}
+
+			if addr.Hostname == "" {
+				klog.Warningf("skipping hostname %v rewritten to an empty hostname", hostname)
+				continue
+			}
+
+			if hasHostname(result, addr.Hostname) {
+				continue
+			}
+		}
+
+		result = append(result, addr)
+	}
+
+	sortLoadBalancerIngress(result)
+
+	return result
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestHostnameRewrite(t *testing.T) {
+	rewrites, err := parseHostnameRewrites([]HostnameRewrite{
+		{
+			Pattern:     `^[a-z0-9]+-elb\.([a-z0-9-]+)\.amazonaws\.com$`,
+			Replacement: "ingress.$1.example.com",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "a1b2-elb.us-east-1.amazonaws.com,10.0.0.1,foo.bar.com"
+	fk.hostnameRewrites = rewrites
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.1"},
+		{Hostname: "foo.bar.com"},
+		{Hostname: "ingress.us-east-1.example.com"},
+	}
+	if status := fk.lastStatus.get(); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestHostnameRewriteDuplicates(t *testing.T) {
+	rewrites, err := parseHostnameRewrites([]HostnameRewrite{
+		{Pattern: `^lb-[0-9]+\.example\.com$`, Replacement: "lb.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fk := buildStatusSync()
+	fk.hostnameRewrites = rewrites
+
+	status := fk.rewriteHostnames([]apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.1"},
+		{Hostname: "lb-1.example.com"},
+		{Hostname: "lb-2.example.com"},
+	})
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "lb.example.com"}}
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestHostnameRewriteEmpty(t *testing.T) {
+	rewrites, err := parseHostnameRewrites([]HostnameRewrite{
+		{Pattern: `^.*\.internal$`, Replacement: ""},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fk := buildStatusSync()
+	fk.hostnameRewrites = rewrites
+
+	status := fk.rewriteHostnames([]apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.1"},
+		{Hostname: "lb.internal"},
+		{Hostname: "lb.example.com"},
+	})
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "lb.example.com"}}
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestInvalidHostnameRewrite(t *testing.T) {
+	_, err := NewStatusSyncer(Config{
+		Client:          buildSimpleClientSet(),
+		IngressLister:   buildIngressLister(),
+		HostnameRewrite: []HostnameRewrite{{Pattern: "a(b"}},
+	})
+	if err == nil {
+		t.Errorf("expected an error with an invalid hostname rewrite pattern")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// sourceRangesAnnotation is the annotation, without prefix, containing the
+// source ranges allowed by the load balancer of the publish service
+const sourceRangesAnnotation = "lb-source-ranges"
+
+// publishServiceSourceRanges returns the loadBalancerSourceRanges of the
+// publish service to write in the lb-source-ranges annotation, empty if it
+// has no source ranges. Returns false if PublishSourceRanges is not set,
+// there is no publish service or the service cannot be read.
+func (s *statusSync) publishServiceSourceRanges() (string, bool) {
+	if !s.PublishSourceRanges || s.PublishStatusAddress != "" {
+		return "", false
+	}
+
+	svc, ok, err := s.getPublishService()
+	if !ok {
+		return "", false
+	}
+
+	if err != nil {
+		klog.Warningf("error obtaining the source ranges of the publish service: %v", err)
+		return "", false
+	}
+
+	ranges := make([]string, 0, len(svc.Spec.LoadBalancerSourceRanges))
+	for _, r := range svc.Spec.LoadBalancerSourceRanges {
+		if r = strings.TrimSpace(r); r != "" {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return strings.Join(ranges, ","), true
+}
+
+// sourceRangesAnnotations returns the lb-source-ranges annotation to write
+// in the Ingress, or nil if the annotation is up to date. Without source
+// ranges, the annotation is returned to be removed.
+func sourceRangesAnnotations(ing *networking.Ingress, ranges string) (map[string]string, []string) {
+	key := parser.GetAnnotationWithPrefix(sourceRangesAnnotation)
+	if ranges == "" {
+		return nil, staleAnnotation(ing, key)
+	}
+
+	if value, ok := ing.Annotations[key]; ok && value == ranges {
+		return nil, nil
+	}
+
+	return map[string]string{key: ranges}, nil
+}
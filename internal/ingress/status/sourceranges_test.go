@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// buildServiceWithSourceRanges returns a load balancer service allowing
+// only some source ranges
+func buildServiceWithSourceRanges() *apiv1.Service {
+	svc := buildServiceWithPorts()
+	svc.Name = "foo_source_ranges"
+	svc.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8", " 192.168.0.0/16"}
+	return svc
+}
+
+func TestPublishSourceRanges(t *testing.T) {
+	key := parser.GetAnnotationWithPrefix(sourceRangesAnnotation)
+
+	testCases := map[string]struct {
+		enabled  bool
+		service  string
+		expected string
+	}{
+		"source ranges":    {enabled: true, service: "default/foo_source_ranges", expected: "10.0.0.0/8,192.168.0.0/16"},
+		"no source ranges": {enabled: true, service: "default/foo_ports", expected: ""},
+		"disabled":         {enabled: false, service: "default/foo_source_ranges", expected: ""},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			client := testclient.NewSimpleClientset(buildServiceWithPorts(), buildServiceWithSourceRanges(), &networking.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo_ingress",
+					Namespace: apiv1.NamespaceDefault,
+				},
+			})
+
+			fk := buildStatusSync()
+			fk.Client = client
+			fk.PublishService = tc.service
+			fk.IngressLister = &clientIngressLister{client}
+			fk.PublishSourceRanges = tc.enabled
+
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ing.Annotations[key] != tc.expected {
+				t.Errorf("returned %q but expected %q", ing.Annotations[key], tc.expected)
+			}
+		})
+	}
+}
+
+func TestPublishSourceRangesRemoved(t *testing.T) {
+	key := parser.GetAnnotationWithPrefix(sourceRangesAnnotation)
+	client := testclient.NewSimpleClientset(buildServiceWithSourceRanges(), &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = "default/foo_source_ranges"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishSourceRanges = true
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the service no longer restricts the source ranges
+	svc := buildServiceWithSourceRanges()
+	svc.Spec.LoadBalancerSourceRanges = nil
+	if _, err := client.CoreV1().Services(apiv1.NamespaceDefault).Update(context.TODO(), svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ing.Annotations[key]; ok {
+		t.Errorf("expected the annotation to be removed but returned %v", ing.Annotations)
+	}
+}
+
+func TestPublishSourceRangesMissingService(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = "default/missing"
+	fk.PublishSourceRanges = true
+
+	if ranges, ok := fk.publishServiceSourceRanges(); ok {
+		t.Errorf("expected no source ranges but returned %v", ranges)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/klog/v2"
+)
+
+// addressStabilizer delays the publication of a new set of addresses until
+// it persists for a period, so addresses flapping between two sets do not
+// rewrite the status on every change
+type addressStabilizer struct {
+	lock sync.Mutex
+
+	clock  clock.Clock
+	period time.Duration
+
+	// enqueue triggers a sync when the candidate addresses become stable
+	enqueue func(reason string)
+
+	// stable contains the addresses being published
+	stable []apiv1.LoadBalancerIngress
+	// initialized is true after the first addresses are published
+	initialized bool
+	// candidate contains the new addresses, published once stable
+	candidate []apiv1.LoadBalancerIngress
+	// since is the time the candidate addresses were first seen
+	since time.Time
+	// scheduled is true while a sync is scheduled for the candidate
+	scheduled bool
+}
+
+func newAddressStabilizer(c clock.Clock, period time.Duration, enqueue func(reason string)) *addressStabilizer {
+	return &addressStabilizer{
+		clock:   c,
+		period:  period,
+		enqueue: enqueue,
+	}
+}
+
+// stabilize returns the addresses to publish: the new addresses once they
+// persisted for the period, or the previous stable addresses
+func (a *addressStabilizer) stabilize(status []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	if a == nil || a.period <= 0 {
+		return status
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := a.clock.Now()
+	switch {
+	case !a.initialized:
+		// the first addresses are published right away
+		a.stable = status
+		a.initialized = true
+	case sameAddresses(status, a.stable):
+		a.candidate = nil
+	case a.candidate == nil || !sameAddresses(status, a.candidate):
+		klog.V(2).InfoS("waiting for the addresses to stabilize before publishing them", "addresses", status, "period", a.period)
+		a.candidate = status
+		a.since = now
+		a.schedule(a.period)
+	case now.Sub(a.since) >= a.period:
+		a.stable = status
+		a.candidate = nil
+	default:
+		a.schedule(a.period - now.Sub(a.since))
+	}
+
+	return a.stable
+}
+
+// schedule triggers a sync after the delay, unless one is already scheduled
+func (a *addressStabilizer) schedule(delay time.Duration) {
+	if a.scheduled || a.enqueue == nil {
+		return
+	}
+
+	a.scheduled = true
+	timer := a.clock.NewTimer(delay)
+	go func() {
+		<-timer.C()
+
+		a.lock.Lock()
+		a.scheduled = false
+		a.lock.Unlock()
+
+		a.enqueue("address stabilization")
+	}()
+}
+
+// sameAddresses compares the addresses ignoring the order
+func sameAddresses(lhs, rhs []apiv1.LoadBalancerIngress) bool {
+	added, removed := diffLoadBalancerIngress(lhs, rhs)
+	return len(added) == 0 && len(removed) == 0
+}
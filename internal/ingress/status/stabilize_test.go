@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestAddressStabilizationPeriod(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	counter := &syncCounter{}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.stabilizer = newAddressStabilizer(fakeClock, 10*time.Second, counter.enqueue)
+
+	publish := func(addr string, expected string) {
+		fk.PublishStatusAddress = addr
+		if _, err := fk.reconcile(context.TODO()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		status := []apiv1.LoadBalancerIngress{{IP: expected}}
+		if !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, status) {
+			t.Fatalf("publishing %v: returned %v but expected %v", addr, ing.Status.LoadBalancer.Ingress, status)
+		}
+	}
+
+	// the first addresses are published right away
+	publish("10.0.0.1", "10.0.0.1")
+
+	// the addresses flapping between two sets are not published
+	for i := 0; i < 5; i++ {
+		fakeClock.Step(3 * time.Second)
+		publish("10.0.0.2", "10.0.0.1")
+
+		fakeClock.Step(3 * time.Second)
+		publish("10.0.0.1", "10.0.0.1")
+	}
+
+	// the new addresses are published once stable
+	fakeClock.Step(3 * time.Second)
+	publish("10.0.0.2", "10.0.0.1")
+
+	fakeClock.Step(9 * time.Second)
+	publish("10.0.0.2", "10.0.0.1")
+
+	fakeClock.Step(time.Second)
+	publish("10.0.0.2", "10.0.0.2")
+}
+
+func TestAddressStabilizationSync(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	counter := &syncCounter{}
+	stabilizer := newAddressStabilizer(fakeClock, 10*time.Second, counter.enqueue)
+
+	stabilizer.stabilize([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}})
+	stabilizer.stabilize([]apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}})
+	if n := counter.count(); n != 0 {
+		t.Fatalf("expected no sync but returned %v", n)
+	}
+
+	// a sync is triggered when the new addresses become stable
+	fakeClock.Step(10 * time.Second)
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return counter.count() == 1, nil
+	})
+	if err != nil {
+		t.Fatalf("expected one sync but returned %v", counter.count())
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	if result := stabilizer.stabilize(expected); !ingressSliceEqual(result, expected) {
+		t.Errorf("returned %v but expected %v", result, expected)
+	}
+}
+
+func TestAddressStabilizationDisabled(t *testing.T) {
+	var stabilizer *addressStabilizer
+
+	status := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if result := stabilizer.stabilize(status); !ingressSliceEqual(result, status) {
+		t.Errorf("returned %v but expected %v", result, status)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+)
+
+// validateStaticAddresses checks the static addresses are IP addresses or
+// DNS hostnames
+func validateStaticAddresses(addrs []string) error {
+	for _, addr := range addrs {
+		if !isValidAddress(addr) {
+			return fmt.Errorf("invalid static address %q", addr)
+		}
+	}
+
+	return nil
+}
+
+// withStaticAddresses returns the addresses with the configured
+// StaticAddresses not already present
+func (s *statusSync) withStaticAddresses(addrs []string) []string {
+	for _, addr := range s.StaticAddresses {
+		if !stringInSlice(addr, addrs) {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs
+}
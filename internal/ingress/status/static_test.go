@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStaticAddresses(t *testing.T) {
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1,10.0.0.2"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.StaticAddresses = []string{"192.0.2.10", "10.0.0.2"}
+	fk.AddressPriority = []string{"192.0.2.0/24"}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the VIP is published first, without duplicating the dynamic address
+	expected := []apiv1.LoadBalancerIngress{{IP: "192.0.2.10"}, {IP: "10.0.0.1"}, {IP: "10.0.0.2"}}
+	if status := getIngressStatus(t, client, "foo_ingress"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+
+	// the static address is kept when the running addresses change
+	fk.PublishStatusAddress = "10.0.0.3"
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []apiv1.LoadBalancerIngress{{IP: "192.0.2.10"}, {IP: "10.0.0.2"}, {IP: "10.0.0.3"}}
+	if status := getIngressStatus(t, client, "foo_ingress"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestValidateStaticAddresses(t *testing.T) {
+	testCases := []struct {
+		addrs []string
+		valid bool
+	}{
+		{nil, true},
+		{[]string{"192.0.2.10", "2001:db8::1", "vip.example.com"}, true},
+		{[]string{"192.0.2.10", ""}, false},
+		{[]string{"not a vip!"}, false},
+	}
+
+	for _, tc := range testCases {
+		err := validateStaticAddresses(tc.addrs)
+		if (err == nil) != tc.valid {
+			t.Errorf("%v: unexpected error %v", tc.addrs, err)
+		}
+	}
+}
@@ -17,24 +17,42 @@ limitations under the License.
 package status
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"path"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
+	"github.com/prometheus/client_golang/prometheus"
 	pool "gopkg.in/go-playground/pool.v3"
 	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 
 	"k8s.io/ingress-nginx/internal/ingress"
 	"k8s.io/ingress-nginx/internal/k8s"
@@ -45,11 +63,50 @@ import (
 // which the status should check if an update is required.
 var UpdateInterval = 60
 
+// emptyListerGracePeriod is the time after the start of the syncer during
+// which an empty Ingress lister is considered not synced yet
+const emptyListerGracePeriod = 30 * time.Second
+
+// errIngressListerNotReady is returned by a sync running before the
+// Ingress lister is synced, to retry it instead of updating no Ingress
+var errIngressListerNotReady = errors.New("the Ingress lister contains no Ingress, waiting for the initial sync")
+
 // Syncer ...
 type Syncer interface {
 	Run(chan struct{})
 
-	Shutdown()
+	Shutdown() error
+
+	// ClearStatus removes the addresses from the status of the Ingress
+	ClearStatus(namespace, name string) error
+
+	// PrepareShutdown removes the node of the controller pod from the
+	// status when the pod starts terminating, before it stops serving
+	PrepareShutdown() error
+
+	// Pause stops the updates of the Ingress status until Resume is called
+	Pause()
+
+	// Resume restarts the updates of the Ingress status
+	Resume()
+
+	// UpdateErrors returns the last error updating the status of each
+	// Ingress, indexed by namespace/name
+	UpdateErrors() map[string]UpdateError
+
+	// EnqueueIngress enqueues the update of the status of a new Ingress
+	EnqueueIngress(ing *networking.Ingress)
+
+	// SyncDiff returns the Ingresses with a status different from the
+	// addresses to publish, without updating them nor the state of the syncer
+	SyncDiff(ctx context.Context) ([]IngressStatusDiff, error)
+
+	// AddressState returns the state of the running addresses computed
+	// by the last sync
+	AddressState() AddressState
+
+	// LastSyncDuration returns the duration of the last sync
+	LastSyncDuration() time.Duration
 }
 
 type ingressLister interface {
@@ -63,13 +120,320 @@ type Config struct {
 
 	PublishService string
 
+	// PublishServiceSelector selects the service used to publish the
+	// addresses when PublishService is not set. Exactly one service
+	// must match the selector.
+	PublishServiceSelector labels.Selector
+
 	PublishStatusAddress string
 
+	// StaticAddresses are always published along with the running addresses,
+	// like a fixed VIP. They are sorted following AddressPriority.
+	StaticAddresses []string
+
 	UpdateStatusOnShutdown bool
 
 	UseNodeInternalIP bool
 
 	IngressLister ingressLister
+
+	// NodeSelector restricts the nodes that contribute addresses when the
+	// status is obtained from the nodes running the controller pods.
+	// A nil selector matches every node.
+	NodeSelector labels.Selector
+
+	// PublishZones restricts the nodes that contribute addresses to the ones
+	// in the listed zones, from the topology.kubernetes.io/zone label. Nodes
+	// in every zone contribute addresses if empty.
+	PublishZones []string
+
+	// PublishTaintedNodes includes the addresses of nodes with a NoSchedule
+	// or NoExecute taint, which are skipped by default
+	PublishTaintedNodes bool
+
+	// PublishAddressTypes lists the types of node addresses to publish. All
+	// the addresses of the listed types are used. When empty, only one address
+	// per node is published, the external IP unless UseNodeInternalIP is set.
+	PublishAddressTypes []apiv1.NodeAddressType
+
+	// ResolveHostnamesForEquality resolves the hostnames in the status to
+	// IP addresses before comparing the current and the new status, so an
+	// address reported as a hostname or as the IP it resolves to is
+	// considered the same. Hostnames that cannot be resolved are compared
+	// literally.
+	ResolveHostnamesForEquality bool
+
+	// StatusWriter writes the addresses in the status of the Ingresses.
+	// Defaults to a writer updating the Ingress status in the API server.
+	StatusWriter StatusWriter
+
+	// StatusToAnnotation writes the addresses in the annotation
+	// nginx.ingress.kubernetes.io/address instead of the Ingress status,
+	// for Ingresses where the status cannot be modified
+	StatusToAnnotation bool
+
+	// MetricsRegisterer is used to register the metrics of the status
+	// synchronization. Defaults to prometheus.DefaultRegisterer
+	MetricsRegisterer prometheus.Registerer
+
+	// NodeAddressAnnotation is the name of a node annotation containing
+	// the address, or a comma separated list of addresses, to publish for
+	// the node instead of the addresses reported in the node status
+	NodeAddressAnnotation string
+
+	// AddressDrainPeriod is the time an address removed from the running
+	// addresses stays in the status before being removed. Zero removes the
+	// address immediately.
+	AddressDrainPeriod time.Duration
+
+	// SyncWorkers is the number of concurrent workers processing the status
+	// sync queue. Defaults to one.
+	SyncWorkers int
+
+	// OnlyClaimDefaultWhenElected restricts the update of the status of
+	// Ingresses without a class to the controller running the IngressClass
+	// marked as default in the cluster
+	OnlyClaimDefaultWhenElected bool
+
+	// DefaultClassBehavior decides how the status of Ingresses without a class
+	// is handled when several controllers run in the cluster. Defaults to
+	// Claim, or ClaimIfDefault with OnlyClaimDefaultWhenElected.
+	DefaultClassBehavior DefaultClassBehavior
+
+	// AddressPriority is an ordered list of CIDRs or glob patterns. Published
+	// addresses matching an entry are placed before the rest, following the
+	// order of the list. Addresses without a match keep their relative order.
+	// The priority decides which addresses are kept by MaxPublishedAddresses.
+	AddressPriority []string
+
+	// MaxPublishedAddresses is the max number of addresses published in the
+	// status of each Ingress. Only the first addresses are published, after
+	// sorting them and moving the ones matching AddressPriority to the front.
+	// Unlimited if zero.
+	MaxPublishedAddresses int
+
+	// AddressHealthCheck probes the addresses before publishing them,
+	// removing the unreachable ones. Disabled if nil.
+	AddressHealthCheck *AddressHealthCheck
+
+	// ExcludeCIDRs is a list of CIDRs. Node addresses contained in any
+	// of them are not published in the status of the Ingresses.
+	ExcludeCIDRs []string
+
+	// StatusReportConfigMap is the namespace/name of a ConfigMap updated
+	// after each sync with the published addresses and the number of
+	// updated, skipped and failed Ingresses. Disabled if empty.
+	StatusReportConfigMap string
+
+	// VIPPoolConfigMap is the namespace/name of a ConfigMap mapping keys
+	// to VIPs. Ingresses with the vip annotation publish the VIP of the
+	// key instead of the controller addresses. Disabled if empty.
+	VIPPoolConfigMap string
+
+	// SyncSummaryLogLevel is the klog verbosity of the line logged after
+	// each sync with the number of examined, updated, skipped and failed
+	// Ingresses and the published addresses
+	SyncSummaryLogLevel klog.Level
+
+	// Recorder emits an Event in each Ingress after its status is updated.
+	// Disabled if nil.
+	Recorder record.EventRecorder
+
+	// StatusEventReason and StatusEventMessage are the text/template
+	// templates of the reason and the message of the status Events, with
+	// the fields Namespace, Name and Addresses (a comma separated list).
+	// DefaultStatusEventReason and DefaultStatusEventMessage if empty.
+	StatusEventReason  string
+	StatusEventMessage string
+
+	// APICallTimeout is the max duration of each API call made to update
+	// the status of an Ingress. Disabled if zero.
+	APICallTimeout time.Duration
+
+	// AllowedHostnameSuffixes restricts the hostnames published in the
+	// status to the ones ending with any of the domains in the list.
+	// IP addresses are always published. Disabled if empty.
+	AllowedHostnameSuffixes []string
+
+	// PreserveUnknownStatusEntries keeps the entries of the Ingress status
+	// not written by the controller, like addresses added manually. The
+	// addresses written by the controller are recorded in an annotation.
+	PreserveUnknownStatusEntries bool
+
+	// UseMergePatch writes the status with a JSON merge patch of the status
+	// subresource instead of updating the whole object
+	UseMergePatch bool
+
+	// AddressResolvers return additional addresses to publish, like the
+	// external IP of the instance from the cloud metadata
+	AddressResolvers []AddressResolver
+
+	// NodeIPEnvVar is the name of an environment variable containing the
+	// IP address of the node running the controller, like NODE_IP set with
+	// the downward API. The address is published in addition to the others.
+	NodeIPEnvVar string
+
+	// PublishHostStatus writes the published addresses in an annotation
+	// host-status.<annotations prefix>/<host> for each host of the rules of
+	// the Ingresses, in addition to the status. Hosts not valid as the name
+	// of an annotation, like wildcard hosts, are skipped.
+	PublishHostStatus bool
+
+	// PublishProxyProtocol sets the proxy-protocol annotation in the
+	// Ingresses with published addresses, for the tools needing to know
+	// the addresses are fronted by PROXY protocol. It is only metadata.
+	// The annotation is removed when disabled.
+	PublishProxyProtocol bool
+
+	// OnStatusUpdated is called after the status of an Ingress is written,
+	// with its namespace/name and the written addresses. It is called
+	// once the API call returns, and it should not block. Disabled if nil.
+	OnStatusUpdated func(ingressKey string, addrs []apiv1.LoadBalancerIngress)
+
+	// ReconcileDone receives the namespace/name of each Ingress after its
+	// status is written, to wait for the update of a specific Ingress in
+	// tests. The channel should be buffered: the keys are dropped when it
+	// is full, without blocking the sync. Disabled if nil.
+	ReconcileDone chan<- string
+
+	// ControllerVersion is written in the status-controller-version
+	// annotation of the Ingresses when their status changes, to know the
+	// version of the controller that wrote the status. Disabled if empty.
+	ControllerVersion string
+
+	// AddressCommand is a command, with its arguments, printing the
+	// addresses to publish in its standard output, one per line. When set,
+	// the addresses of the command are published instead of the ones of the
+	// publish service or the nodes. Disabled if empty.
+	AddressCommand []string
+
+	// AddressCommandTimeout is the max duration of the AddressCommand.
+	// Defaults to 10 seconds if zero.
+	AddressCommandTimeout time.Duration
+
+	// SelectControllerNodes restricts the node informer to the node running
+	// this controller pod with a field selector, instead of watching all the
+	// nodes of the cluster. The nodes running the other controller pods are
+	// read from the API server when the addresses are published.
+	SelectControllerNodes bool
+
+	// RequireReadyAnnotation is the name of an annotation marking the
+	// Ingresses ready to publish their addresses. When set, the Ingresses
+	// without the annotation are not updated, and the status is cleared
+	// when the value of the annotation is not true. Disabled if empty.
+	RequireReadyAnnotation string
+
+	// PublishWildcardHostnames adds to the status of the Ingresses the
+	// hostname of the wildcard hosts of the rules, without the wildcard
+	// label. The host *.apps.example.com publishes apps.example.com.
+	PublishWildcardHostnames bool
+
+	// InitialSyncDelay delays the first status sync after the leadership is
+	// acquired, giving time to the controller pods to become ready before
+	// their addresses are published. Later syncs are not delayed.
+	InitialSyncDelay time.Duration
+
+	// DebounceWindow coalesces the syncs triggered by changes of the nodes
+	// or the publish service. The first change is synced immediately, and
+	// the changes received in the following window are synced once at its
+	// end. Disabled if zero.
+	DebounceWindow time.Duration
+
+	// AddressStabilizationPeriod delays the publication of new addresses
+	// until they persist for the period, ignoring the addresses flapping
+	// between two sets. The first addresses are published right away.
+	// Disabled if zero.
+	AddressStabilizationPeriod time.Duration
+
+	// EmptyAddressGrace is the time the status of the Ingresses is kept
+	// after the running addresses become empty. The status is cleared in
+	// the first sync after it. It is overridden in each Ingress with the
+	// annotation nginx.ingress.kubernetes.io/empty-address-grace, like
+	// "0s" to clear it immediately. Disabled if zero.
+	EmptyAddressGrace time.Duration
+
+	// RunningAddressCacheTTL is the time the last running addresses
+	// computed without error are published when a transient error, like
+	// the API server being unavailable, prevents obtaining them. Disabled
+	// if zero.
+	RunningAddressCacheTTL time.Duration
+
+	// StatusConflictThreshold is the number of times the status written
+	// in an Ingress has to be changed by another writer, like a second
+	// controller claiming the Ingress, within the StatusConflictWindow to
+	// report a conflict. Each conflict is reported once. Disabled if zero.
+	StatusConflictThreshold int
+
+	// StatusConflictWindow is the period the changes of the status made by
+	// other writers are counted. Ten minutes if zero.
+	StatusConflictWindow time.Duration
+
+	// PublishReadyEndpoints publishes the IP addresses of the ready
+	// endpoints of the publish service instead of the addresses of the
+	// service. The addresses of endpoints not ready are not published.
+	PublishReadyEndpoints bool
+
+	// PublishClusterIP adds the cluster IP of the publish service to the
+	// published addresses. Headless services have no cluster IP. Ignored
+	// with PublishReadyEndpoints.
+	PublishClusterIP bool
+
+	// HostnameRewrite lists the rules applied, in order, to the hostnames
+	// published in the status, like replacing the hostname of a cloud load
+	// balancer with the name of a CNAME pointing to it
+	HostnameRewrite []HostnameRewrite
+
+	// Namespaces restricts the update of the status to the Ingresses in
+	// the listed namespaces. Empty updates the Ingresses in all namespaces.
+	Namespaces []string
+
+	// IngressAllowlist restricts the update of the status to the listed
+	// namespace/name Ingresses, regardless of their class. Empty updates
+	// the Ingresses claimed by the class.
+	IngressAllowlist []string
+
+	// BackendServiceFilter restricts the update of the status to the
+	// Ingresses routing to the namespace/name service. Disabled if empty.
+	BackendServiceFilter string
+
+	// WatchNamespace is the namespace watched by the controller, empty if
+	// the controller watches all the namespaces. Used to validate Namespaces.
+	WatchNamespace string
+
+	// NormalizeStatusEntries canonicalizes the entries of the status before
+	// comparing and publishing them: spaces are trimmed, hostnames with an IP
+	// address are published as IP, and empty and duplicated entries are removed
+	NormalizeStatusEntries bool
+
+	// PublishServicePorts writes the ports of the publish service in the
+	// annotation nginx.ingress.kubernetes.io/status-ports of the Ingresses,
+	// as port/protocol pairs like 80/TCP,443/TCP
+	PublishServicePorts bool
+
+	// PublishSourceRanges writes the loadBalancerSourceRanges of the
+	// publish service in the annotation
+	// nginx.ingress.kubernetes.io/lb-source-ranges of the Ingresses, as a
+	// comma separated list. It is only informational.
+	PublishSourceRanges bool
+
+	// OwnershipPredicate is called for the Ingresses claimed by the
+	// controller after the class filtering. Returning false skips the
+	// update of the status of the Ingress. Ignored if nil.
+	OwnershipPredicate func(ing *networking.Ingress) bool
+
+	// LoadBalancerClass restricts the update of the status to the Ingresses
+	// with this spec.loadBalancerClass or, when the Ingress has no class,
+	// routing to a Service with this class. Empty updates all the Ingresses.
+	// Requires DynamicClient.
+	LoadBalancerClass string
+
+	// DynamicClient reads the loadBalancerClass of the Ingresses and the
+	// Services, not available in the typed API
+	DynamicClient dynamic.Interface
+
+	// TracerProvider is used to trace the status syncs. Defaults to the
+	// global TracerProvider, a no-op unless configured.
+	TracerProvider trace.TracerProvider
 }
 
 // statusSync keeps the status IP in each Ingress rule updated executing a periodic check
@@ -85,12 +449,110 @@ type statusSync struct {
 	// workqueue used to keep in sync the status IP/s
 	// in the Ingress rules
 	syncQueue *task.Queue
+
+	// paused is set to 1 while the updates of the status are suspended
+	paused int32
+
+	// leading is set to 1 while this instance is the leader
+	leading int32
+
+	// terminating is set to 1 when the controller pod starts its shutdown
+	terminating int32
+
+	// syncLock is held for reading by the syncs and for writing by
+	// PrepareShutdown, so no sync started before the terminating flag
+	// finishes after the update of PrepareShutdown
+	syncLock *sync.RWMutex
+
+	// reconcileLock serializes the reconciliations of all the Ingresses,
+	// as they write the same Ingresses and update the address trackers.
+	// The syncs of a single Ingress run concurrently.
+	reconcileLock *sync.Mutex
+
+	// addressState contains the AddressState of the last running addresses
+	addressState int32
+
+	// lastSyncDuration is the duration in nanoseconds of the last sync
+	lastSyncDuration int64
+
+	// lastStatus contains the addresses published in the last sync
+	lastStatus *statusCache
+
+	metrics *statusMetrics
+
+	// drainer keeps the removed addresses during AddressDrainPeriod
+	drainer *addressDrainer
+
+	// updateErrors keeps the last error updating the status of each Ingress
+	updateErrors *updateErrors
+
+	// publishServiceForbidden is set to 1 after reporting the controller
+	// is not allowed to read the publish service
+	publishServiceForbidden int32
+
+	// lookupHost resolves a hostname to IP addresses. Defaults to net.LookupHost
+	lookupHost func(host string) ([]string, error)
+
+	// dial opens the connections of AddressHealthCheck. Defaults to net.DialTimeout
+	dial dialFunc
+
+	// excludeCIDRs contains the parsed ExcludeCIDRs
+	excludeCIDRs []*net.IPNet
+
+	// hostnameRewrites contains the compiled HostnameRewrite rules
+	hostnameRewrites []hostnameRewrite
+
+	// eventTemplates renders the status Events
+	eventTemplates *statusEventTemplates
+
+	// clock is used to wait the InitialSyncDelay
+	clock clock.Clock
+
+	// nodes caches the nodes while the addresses of the nodes are published
+	nodes *nodeCache
+
+	// debouncer coalesces the syncs triggered by address changes
+	debouncer *addressDebouncer
+
+	// stabilizer delays the publication of new addresses
+	stabilizer *addressStabilizer
+
+	// emptyAddresses records since when the published addresses are empty
+	emptyAddresses *emptyAddressTracker
+
+	// addressCache keeps the last running addresses computed without error
+	addressCache *runningAddressCache
+
+	// conflicts detects the Ingresses whose status is changed by another
+	// writer after each update
+	conflicts *statusConflictDetector
+
+	// started is the creation time of the syncer, used to detect an
+	// Ingress lister not synced yet. Disabled if zero.
+	started time.Time
 }
 
 // Start starts the loop to keep the status in sync
-func (s statusSync) Run(stopCh chan struct{}) {
+func (s *statusSync) Run(stopCh chan struct{}) {
+	// Run is invoked when this instance is elected as leader
+	// and returns when the leadership is lost
+	s.setLeading(true)
+	defer s.setLeading(false)
+
+	if !s.waitInitialSyncDelay(stopCh) {
+		return
+	}
+
 	go s.syncQueue.Run(time.Second, stopCh)
 
+	if s.PublishService != "" || s.PublishServiceSelector != nil {
+		s.watchPublishService(stopCh)
+	}
+
+	if s.usesNodeAddresses() {
+		s.watchNodes(stopCh)
+	}
+
 	// trigger initial sync
 	s.syncQueue.EnqueueTask(task.GetDummyObject("sync status"))
 
@@ -102,249 +564,1488 @@ func (s statusSync) Run(stopCh chan struct{}) {
 	}, stopCh)
 }
 
+// setLeading marks this instance as the leader or not. Tests call it to
+// sync the status as the leader without running an election.
+func (s *statusSync) setLeading(leading bool) {
+	var value int32
+	if leading {
+		value = 1
+	}
+	atomic.StoreInt32(&s.leading, value)
+}
+
+// waitInitialSyncDelay waits the InitialSyncDelay before the first sync.
+// The elements enqueued in the meantime are processed after the delay.
+// Returns false if stopCh is closed first.
+func (s *statusSync) waitInitialSyncDelay(stopCh chan struct{}) bool {
+	if s.InitialSyncDelay <= 0 {
+		return true
+	}
+
+	c := s.clock
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	klog.InfoS("delaying the first Ingress status sync", "delay", s.InitialSyncDelay)
+	select {
+	case <-c.After(s.InitialSyncDelay):
+		return true
+	case <-stopCh:
+		return false
+	}
+}
+
 // Shutdown stops the sync. In case the instance is the leader it will remove the current IP
-// if there is no other instances running.
-func (s statusSync) Shutdown() {
+// if there is no other Ready instance. Returns the errors clearing the status.
+func (s *statusSync) Shutdown() error {
 	go s.syncQueue.Shutdown()
 
 	if !s.UpdateStatusOnShutdown {
 		klog.Warningf("skipping update of status of Ingress rules")
-		return
+		return nil
+	}
+
+	if s.isPaused() {
+		klog.Warningf("skipping update of status of Ingress rules (status updates are paused)")
+		return nil
 	}
 
 	addrs, err := s.runningAddresses()
 	if err != nil {
 		klog.ErrorS(err, "error obtaining running IP address")
-		return
+		return nil
 	}
 
 	if len(addrs) > 1 {
 		// leave the job to the next leader
 		klog.InfoS("leaving status update for next leader")
-		return
+		return nil
 	}
 
-	if s.isRunningMultiplePods() {
-		klog.V(2).InfoS("skipping Ingress status update (multiple pods running - another one will be elected as master)")
-		return
+	// during a rolling update the new replicas keep publishing the address
+	ready, err := s.otherReadyPods()
+	if err != nil {
+		klog.ErrorS(err, "error listing the ingress controller pods, skipping the removal of the Ingress status")
+		return nil
+	}
+	if ready > 0 {
+		klog.V(2).InfoS("skipping Ingress status update (other controller pods are ready - another one will be elected as master)", "ready", ready)
+		return nil
 	}
 
 	klog.InfoS("removing value from ingress status", "address", addrs)
-	s.updateStatus([]apiv1.LoadBalancerIngress{})
+	return s.clearStatus()
 }
 
-func (s *statusSync) sync(key interface{}) error {
-	if s.syncQueue.IsShuttingDown() {
-		klog.V(2).InfoS("skipping Ingress status update (shutting down in progress)")
-		return nil
+// clearStatus removes the addresses from the status of the claimed Ingresses.
+// A failure does not stop the update of the rest of the Ingresses, conflicts
+// are retried and the errors are returned as an aggregate. Ingresses without
+// addresses are not updated, so it can be called again after a failure.
+func (s *statusSync) clearStatus() error {
+	ings := s.listIngresses()
+	equal := s.statusEqualFunc()
+	writer := s.statusWriter()
+
+	var errs []error
+	for _, ing := range ings {
+		key := fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)
+		if !s.isClaimed(ing) {
+			continue
+		}
+
+		curIPs := s.currentAddresses(&ing.Ingress)
+		s.sortStatus(curIPs)
+		if equal(curIPs, s.desiredStatus(&ing.Ingress, []apiv1.LoadBalancerIngress{})) {
+			continue
+		}
+
+		if err := s.clearIngressStatus(ing, writer); err != nil {
+			errs = append(errs, fmt.Errorf("clearing status of Ingress %v: %w", key, err))
+		}
 	}
 
-	addrs, err := s.runningAddresses()
+	return utilerrors.NewAggregate(errs)
+}
+
+// ClearStatus removes the addresses from the status of a single Ingress,
+// like a stuck one. Only the leader updates the status.
+func (s *statusSync) ClearStatus(namespace, name string) error {
+	if atomic.LoadInt32(&s.leading) == 0 {
+		return fmt.Errorf("the status of Ingress %v/%v is only updated by the leader", namespace, name)
+	}
+
+	for _, ing := range s.listIngresses() {
+		if ing.Namespace != namespace || ing.Name != name {
+			continue
+		}
+
+		klog.InfoS("clearing the Ingress status", "namespace", namespace, "ingress", name)
+		return s.clearIngressStatus(ing, s.statusWriter())
+	}
+
+	return apierrors.NewNotFound(networking.Resource("ingresses"), name)
+}
+
+// clearIngressStatus writes an empty status in the Ingress, retrying the
+// conflicts with the current version of the Ingress
+func (s *statusSync) clearIngressStatus(ing *ingress.Ingress, writer StatusWriter) error {
+	key := fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)
+
+	current := ing
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := writer.Write(current, []apiv1.LoadBalancerIngress{})
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+
+		latest, getErr := getIngress(s.Client.NetworkingV1beta1().Ingresses(ing.Namespace), ing.Name, s.APICallTimeout)
+		if getErr != nil {
+			return getErr
+		}
+
+		refreshed := *ing
+		refreshed.Ingress = *latest
+		current = &refreshed
+		return err
+	})
 	if err != nil {
+		klog.ErrorS(err, "error clearing the Ingress status", "namespace", ing.Namespace, "ingress", ing.Name)
+		s.updateErrors.set(key, err)
 		return err
 	}
-	s.updateStatus(sliceToStatus(addrs))
 
+	s.updateErrors.clear(key)
 	return nil
 }
 
-func (s statusSync) keyfunc(input interface{}) (interface{}, error) {
-	return input, nil
+// syncResult describes the changes made by a reconciliation of the status
+type syncResult struct {
+	// updated is the number of Ingresses with a new status
+	updated int
+	// skipped is the number of Ingresses already in sync
+	skipped int
+	// failed is the number of Ingresses that could not be updated
+	failed int
+	// retryable is the number of failed updates that should be retried
+	retryable int
+	// changed contains the namespace/name of the updated Ingresses
+	changed []string
 }
 
-// NewStatusSyncer returns a new Syncer instance
-func NewStatusSyncer(config Config) Syncer {
-	st := statusSync{
-		Config: config,
+func (s *statusSync) sync(key interface{}) error {
+	s.metrics.observeQueueLatency(key)
+	defer s.observeSyncDuration(s.now())
+
+	ctx, span := s.startSpan(withEnqueued(context.Background(), key), "status.sync")
+
+	var result syncResult
+	var err error
+	s.syncLock.RLock()
+	if ik, ok := ingressKeyFromElement(key); ok {
+		result, err = s.syncIngress(ctx, ik)
+	} else {
+		result, err = s.reconcile(ctx)
+	}
+	s.syncLock.RUnlock()
+	span.SetAttributes(resultAttributes(result)...)
+	endSpan(span, err)
+	s.metrics.observeSyncResult(result)
+
+	if err != nil {
+		if !isRetryableError(err) {
+			return task.NewNonRetryableError(err)
+		}
+
+		return err
+	}
+
+	s.logSyncSummary(result)
+
+	if result.retryable > 0 {
+		return fmt.Errorf("%v Ingress status updates failed with a transient error", result.retryable)
 	}
-	st.syncQueue = task.NewCustomTaskQueue(st.sync, st.keyfunc)
 
-	return st
+	return nil
 }
 
-// runningAddresses returns a list of IP addresses and/or FQDN where the
-// ingress controller is currently running
-func (s *statusSync) runningAddresses() ([]string, error) {
-	if s.PublishStatusAddress != "" {
-		re := regexp.MustCompile(`,\s*`)
-		multipleAddrs := re.Split(s.PublishStatusAddress, -1)
-		return multipleAddrs, nil
+// reconcile updates the status of the Ingresses with the running addresses
+func (s *statusSync) reconcile(ctx context.Context) (syncResult, error) {
+	s.reconcileLock.Lock()
+	defer s.reconcileLock.Unlock()
+
+	if s.isSyncSuspended() {
+		return syncResult{}, nil
 	}
 
-	if s.PublishService != "" {
-		return statusAddressFromService(s.PublishService, s.Client)
+	if s.ingressListerNotReady() {
+		return syncResult{}, errIngressListerNotReady
 	}
 
-	// get information about all the pods running the ingress controller
-	pods, err := s.Client.CoreV1().Pods(k8s.IngressPodDetails.Namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(k8s.IngressPodDetails.Labels).String(),
-	})
+	addrs, err := s.publishedAddresses(ctx)
 	if err != nil {
-		return nil, err
+		return syncResult{}, err
 	}
 
-	addrs := make([]string, 0)
-	for i := range pods.Items {
-		pod := pods.Items[i]
-		// only Running pods are valid
-		if pod.Status.Phase != apiv1.PodRunning {
-			continue
-		}
+	if s.AddressDrainPeriod > 0 && s.drainer != nil {
+		addrs = s.drainer.drain(addrs, s.AddressDrainPeriod)
+	}
 
-		// only Ready pods are valid
-		isPodReady := false
-		for _, cond := range pod.Status.Conditions {
-			if cond.Type == apiv1.PodReady && cond.Status == apiv1.ConditionTrue {
-				isPodReady = true
-				break
-			}
-		}
+	status := s.rewriteHostnames(sliceToStatus(addrs))
+	if s.NormalizeStatusEntries {
+		status = normalizeLoadBalancerIngress(status)
+	}
 
-		if !isPodReady {
-			klog.InfoS("POD is not ready", "pod", klog.KObj(&pod), "node", pod.Spec.NodeName)
-			continue
-		}
+	status = s.stabilizer.stabilize(status)
 
-		name := k8s.GetNodeIPOrName(s.Client, pod.Spec.NodeName, s.UseNodeInternalIP)
-		if !stringInSlice(name, addrs) {
-			addrs = append(addrs, name)
-		}
+	// an empty status is only written when the controller is confirmed
+	// to be running in no address
+	if len(status) == 0 && s.AddressState() != AddressStateEmpty {
+		klog.Warningf("skipping update of the Ingress status without addresses (running addresses state: %v)", s.AddressState())
+		return syncResult{}, nil
 	}
 
-	return addrs, nil
+	if s.emptyAddresses != nil {
+		s.emptyAddresses.observe(len(status) == 0)
+	}
+
+	result := s.updateStatus(ctx, status)
+	s.lastStatus.set(status)
+	s.writeReport(status, result)
+
+	return result, nil
 }
 
-func (s *statusSync) isRunningMultiplePods() bool {
-	pods, err := s.Client.CoreV1().Pods(k8s.IngressPodDetails.Namespace).List(context.TODO(), metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(k8s.IngressPodDetails.Labels).String(),
-	})
-	if err != nil {
+// ingressListerNotReady returns true if the Ingress lister is empty shortly
+// after the start of the syncer, when its cache is probably not synced yet
+func (s *statusSync) ingressListerNotReady() bool {
+	if s.started.IsZero() || s.clock == nil {
 		return false
 	}
 
-	return len(pods.Items) > 1
+	if s.clock.Since(s.started) > emptyListerGracePeriod {
+		return false
+	}
+
+	return len(s.IngressLister.ListIngresses()) == 0
 }
 
-// sliceToStatus converts a slice of IP and/or hostnames to LoadBalancerIngress
-func sliceToStatus(endpoints []string) []apiv1.LoadBalancerIngress {
-	lbi := []apiv1.LoadBalancerIngress{}
-	for _, ep := range endpoints {
-		if net.ParseIP(ep) == nil {
-			lbi = append(lbi, apiv1.LoadBalancerIngress{Hostname: ep})
-		} else {
-			lbi = append(lbi, apiv1.LoadBalancerIngress{IP: ep})
-		}
+// publishedAddresses returns the running addresses allowed and healthy
+// to be published in the status
+func (s *statusSync) publishedAddresses(ctx context.Context) ([]string, error) {
+	_, span := s.startSpan(ctx, "status.runningAddresses")
+	addrs, err := s.runningAddresses()
+	if err == nil && s.addressCache != nil {
+		s.addressCache.set(addrs)
+	} else if cached, ok := s.cachedRunningAddresses(err); ok {
+		klog.Warningf("using the last known running addresses %v: %v", cached, err)
+		addrs, err = cached, nil
+	}
+	s.setAddressState(addrs, err)
+	span.SetAttributes(attribute.Int("addresses", len(addrs)))
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
 	}
 
-	sort.SliceStable(lbi, func(a, b int) bool {
-		return lbi[a].IP < lbi[b].IP
-	})
+	addrs = s.resolvedAddresses(ctx, addrs)
+	addrs = s.allowedAddresses(addrs)
+	addrs = s.healthyAddresses(addrs)
+	addrs = s.withStaticAddresses(addrs)
 
-	return lbi
+	return addrs, nil
 }
 
-// updateStatus changes the status information of Ingress rules
-func (s *statusSync) updateStatus(newIngressPoint []apiv1.LoadBalancerIngress) {
-	ings := s.IngressLister.ListIngresses()
+// Pause suspends the updates of the Ingress status. The instance keeps
+// the leadership while paused.
+func (s *statusSync) Pause() {
+	if atomic.CompareAndSwapInt32(&s.paused, 0, 1) {
+		klog.InfoS("pausing Ingress status updates")
+	}
+}
 
-	p := pool.NewLimited(10)
-	defer p.Close()
+// Resume restarts the updates of the Ingress status and triggers a sync
+func (s *statusSync) Resume() {
+	if !atomic.CompareAndSwapInt32(&s.paused, 1, 0) {
+		return
+	}
 
-	batch := p.Batch()
-	sort.SliceStable(newIngressPoint, lessLoadBalancerIngress(newIngressPoint))
+	klog.InfoS("resuming Ingress status updates")
+	if !s.syncQueue.IsShuttingDown() {
+		s.syncQueue.EnqueueTask(task.GetDummyObject("sync status"))
+	}
+}
 
-	for _, ing := range ings {
-		curIPs := ing.Status.LoadBalancer.Ingress
-		sort.SliceStable(curIPs, lessLoadBalancerIngress(curIPs))
-		if ingressSliceEqual(curIPs, newIngressPoint) {
-			klog.V(3).InfoS("skipping update of Ingress (no change)", "namespace", ing.Namespace, "ingress", ing.Name)
-			continue
-		}
+// UpdateErrors returns the last error updating the status of each Ingress
+func (s *statusSync) UpdateErrors() map[string]UpdateError {
+	return s.updateErrors.list()
+}
 
-		batch.Queue(runUpdate(ing, newIngressPoint, s.Client))
+// isSyncSuspended returns true if the status must not be updated
+// because the controller is shutting down or the updates are paused
+func (s *statusSync) isSyncSuspended() bool {
+	if s.syncQueue.IsShuttingDown() {
+		klog.V(2).InfoS("skipping Ingress status update (shutting down in progress)")
+		return true
 	}
 
-	batch.QueueComplete()
-	batch.WaitAll()
+	if s.isPaused() {
+		klog.InfoS("skipping Ingress status update (status updates are paused)")
+		return true
+	}
+
+	return false
 }
 
-func runUpdate(ing *ingress.Ingress, status []apiv1.LoadBalancerIngress,
-	client clientset.Interface) pool.WorkFunc {
-	return func(wu pool.WorkUnit) (interface{}, error) {
-		if wu.IsCancelled() {
-			return nil, nil
-		}
+func (s *statusSync) isPaused() bool {
+	return atomic.LoadInt32(&s.paused) == 1
+}
 
-		ingClient := client.NetworkingV1beta1().Ingresses(ing.Namespace)
-		currIng, err := ingClient.Get(context.TODO(), ing.Name, metav1.GetOptions{})
-		if err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("unexpected error searching Ingress %v/%v", ing.Namespace, ing.Name))
-		}
+func (s *statusSync) keyfunc(input interface{}) (interface{}, error) {
+	return input, nil
+}
 
-		klog.InfoS("updating Ingress status", "namespace", currIng.Namespace, "ingress", currIng.Name, "currentValue", currIng.Status.LoadBalancer.Ingress, "newValue", status)
-		currIng.Status.LoadBalancer.Ingress = status
-		_, err = ingClient.UpdateStatus(context.TODO(), currIng, metav1.UpdateOptions{})
-		if err != nil {
-			klog.Warningf("error updating ingress rule: %v", err)
-		}
+// NewStatusSyncer returns a new Syncer instance
+func NewStatusSyncer(config Config) (Syncer, error) {
+	excludeCIDRs, err := parseCIDRs(config.ExcludeCIDRs)
+	if err != nil {
+		return nil, err
+	}
 
-		return true, nil
+	hostnameRewrites, err := parseHostnameRewrites(config.HostnameRewrite)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func lessLoadBalancerIngress(addrs []apiv1.LoadBalancerIngress) func(int, int) bool {
-	return func(a, b int) bool {
-		switch strings.Compare(addrs[a].Hostname, addrs[b].Hostname) {
-		case -1:
-			return true
-		case 1:
-			return false
-		}
-		return addrs[a].IP < addrs[b].IP
+	eventTemplates, err := parseStatusEventTemplates(config.StatusEventReason, config.StatusEventMessage)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func ingressSliceEqual(lhs, rhs []apiv1.LoadBalancerIngress) bool {
-	if len(lhs) != len(rhs) {
-		return false
+	if err := validateNamespaces(config.Namespaces, config.WatchNamespace); err != nil {
+		return nil, err
 	}
 
-	for i := range lhs {
-		if lhs[i].IP != rhs[i].IP {
-			return false
-		}
-		if lhs[i].Hostname != rhs[i].Hostname {
-			return false
+	if err := validateIngressAllowlist(config.IngressAllowlist); err != nil {
+		return nil, err
+	}
+
+	if err := validateDefaultClassBehavior(config.DefaultClassBehavior); err != nil {
+		return nil, err
+	}
+
+	if config.BackendServiceFilter != "" {
+		if _, _, err := k8s.ParseNameNS(config.BackendServiceFilter); err != nil {
+			return nil, fmt.Errorf("invalid backend service filter %q: %v", config.BackendServiceFilter, err)
 		}
 	}
 
-	return true
-}
+	if config.LoadBalancerClass != "" && config.DynamicClient == nil {
+		return nil, fmt.Errorf("load balancer class %q requires a dynamic client", config.LoadBalancerClass)
+	}
 
-func statusAddressFromService(service string, kubeClient clientset.Interface) ([]string, error) {
-	ns, name, _ := k8s.ParseNameNS(service)
-	svc, err := kubeClient.CoreV1().Services(ns).Get(context.TODO(), name, metav1.GetOptions{})
-	if err != nil {
+	if err := validateStaticAddresses(config.StaticAddresses); err != nil {
 		return nil, err
 	}
 
-	switch svc.Spec.Type {
-	case apiv1.ServiceTypeExternalName:
-		return []string{svc.Spec.ExternalName}, nil
-	case apiv1.ServiceTypeClusterIP:
-		return []string{svc.Spec.ClusterIP}, nil
-	case apiv1.ServiceTypeNodePort:
-		addresses := sets.NewString()
-		if svc.Spec.ExternalIPs != nil {
-			addresses.Insert(svc.Spec.ExternalIPs...)
-		} else {
-			addresses.Insert(svc.Spec.ClusterIP)
-		}
-		return addresses.List(), nil
-	case apiv1.ServiceTypeLoadBalancer:
-		addresses := sets.NewString()
-		for _, ip := range svc.Status.LoadBalancer.Ingress {
-			if ip.IP == "" {
+	if config.MaxPublishedAddresses < 0 {
+		return nil, fmt.Errorf("invalid max number of published addresses %v", config.MaxPublishedAddresses)
+	}
+
+	if config.NodeIPEnvVar != "" {
+		// copy the resolvers to avoid modifying the slice of the caller
+		resolvers := make([]AddressResolver, 0, len(config.AddressResolvers)+1)
+		resolvers = append(resolvers, config.AddressResolvers...)
+		config.AddressResolvers = append(resolvers, NewEnvAddressResolver(config.NodeIPEnvVar))
+	}
+
+	st := &statusSync{
+		Config:     config,
+		lookupHost: net.LookupHost,
+		dial:       net.DialTimeout,
+		drainer:    newAddressDrainer(clock.RealClock{}),
+
+		updateErrors:  newUpdateErrors(clock.RealClock{}),
+		excludeCIDRs:  excludeCIDRs,
+		lastStatus:    &statusCache{},
+		syncLock:      &sync.RWMutex{},
+		reconcileLock: &sync.Mutex{},
+		clock:         clock.RealClock{},
+		started:       time.Now(),
+		nodes:         &nodeCache{},
+
+		hostnameRewrites: hostnameRewrites,
+		eventTemplates:   eventTemplates,
+	}
+	st.syncQueue = task.NewCustomTaskQueueN(st.sync, st.keyfunc, config.SyncWorkers)
+	st.debouncer = newAddressDebouncer(clock.RealClock{}, config.DebounceWindow, func(reason string) {
+		st.syncQueue.EnqueueTask(task.GetDummyObject(reason))
+	})
+	st.stabilizer = newAddressStabilizer(clock.RealClock{}, config.AddressStabilizationPeriod, st.enqueueAddressChange)
+	st.emptyAddresses = newEmptyAddressTracker(clock.RealClock{})
+	st.addressCache = newRunningAddressCache(clock.RealClock{})
+	st.conflicts = newStatusConflictDetector(clock.RealClock{})
+
+	reg := config.MetricsRegisterer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	st.metrics = newStatusMetrics(reg, st.syncQueue)
+
+	return st, nil
+}
+
+// runningAddresses returns a list of IP addresses and/or FQDN where the
+// ingress controller is currently running
+func (s *statusSync) runningAddresses() ([]string, error) {
+	if s.PublishStatusAddress != "" {
+		return splitAddresses(s.PublishStatusAddress), nil
+	}
+
+	if len(s.AddressCommand) > 0 {
+		return s.commandAddresses(context.TODO())
+	}
+
+	if s.PublishService != "" {
+		addrs, err := s.publishServiceAddresses(s.PublishService)
+		switch {
+		case err == nil:
+			atomic.StoreInt32(&s.publishServiceForbidden, 0)
+			return addrs, nil
+		case apierrors.IsNotFound(err):
+			klog.Warningf("publish service %v does not exist, using the address of the nodes running the ingress controller pods", s.PublishService)
+		case apierrors.IsForbidden(err):
+			s.warnPublishServiceForbidden(err)
+		default:
+			return nil, err
+		}
+	} else if s.PublishServiceSelector != nil {
+		svc, err := s.publishServiceBySelector()
+		if err != nil {
+			return nil, err
+		}
+
+		if s.PublishReadyEndpoints {
+			return statusAddressFromEndpoints(fmt.Sprintf("%v/%v", svc.Namespace, svc.Name), s.Client)
+		}
+
+		return serviceAddresses(svc, s.PublishClusterIP)
+	}
+
+	return s.nodeRunningAddresses(s.PublishAddressTypes)
+}
+
+// nodeRunningAddresses returns the addresses of the given types of the nodes
+// running the ingress controller pods, or the ones in the publish-address
+// annotation of the pods
+func (s *statusSync) nodeRunningAddresses(addressTypes []apiv1.NodeAddressType) ([]string, error) {
+	// get information about all the pods running the ingress controller
+	pods, err := s.Client.CoreV1().Pods(k8s.IngressPodDetails.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(k8s.IngressPodDetails.Labels).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0)
+	nodes := sets.NewString()
+	for i := range pods.Items {
+		pod := pods.Items[i]
+		// only Running pods are valid
+		if pod.Status.Phase != apiv1.PodRunning {
+			continue
+		}
+
+		// only Ready pods are valid
+		if !isPodReady(&pod) {
+			klog.InfoS("POD is not ready", "pod", klog.KObj(&pod), "node", pod.Spec.NodeName)
+			continue
+		}
+
+		if s.isTerminatingPod(&pod) {
+			klog.InfoS("skipping node of the terminating controller pod", "pod", klog.KObj(&pod), "node", pod.Spec.NodeName)
+			continue
+		}
+
+		// the addresses annotated in the pod are preferred over the ones of the node
+		if podAddrs, ok := podPublishAddresses(&pod); ok {
+			for _, name := range podAddrs {
+				if s.isAddressExcluded(name) {
+					klog.V(3).InfoS("skipping excluded pod address", "pod", klog.KObj(&pod), "address", name)
+					continue
+				}
+
+				if !stringInSlice(name, addrs) {
+					addrs = append(addrs, name)
+				}
+			}
+			continue
+		}
+
+		// the node of several controller pods is fetched once
+		if nodes.Has(pod.Spec.NodeName) {
+			continue
+		}
+		nodes.Insert(pod.Spec.NodeName)
+
+		node, err := s.getNode(pod.Spec.NodeName)
+		if err != nil {
+			klog.ErrorS(err, "Error getting node", "name", pod.Spec.NodeName)
+			continue
+		}
+
+		if !s.isNodePublishable(node) {
+			klog.V(3).InfoS("skipping node address", "pod", klog.KObj(&pod), "node", node.Name)
+			continue
+		}
+
+		for _, name := range s.nodeAddresses(node, addressTypes) {
+			if s.isAddressExcluded(name) {
+				klog.V(3).InfoS("skipping excluded node address", "node", node.Name, "address", name)
+				continue
+			}
+
+			if !stringInSlice(name, addrs) {
+				addrs = append(addrs, name)
+			}
+		}
+	}
+
+	return addrs, nil
+}
+
+// warnPublishServiceForbidden reports the RBAC permission required to read
+// the publish service. Only the first error is logged as a warning to avoid
+// repeating the same message in every sync
+func (s *statusSync) warnPublishServiceForbidden(err error) {
+	ns, name, _ := k8s.ParseNameNS(s.PublishService)
+	if !atomic.CompareAndSwapInt32(&s.publishServiceForbidden, 0, 1) {
+		klog.V(2).InfoS("publish service is not readable, using the address of the nodes", "namespace", ns, "service", name)
+		return
+	}
+
+	klog.Warningf("the ingress controller is not allowed to read the publish service %v (%v). "+
+		"Grant the verb \"get\" on the resource \"services\" in the namespace %q to the ingress controller service account "+
+		"using a Role or ClusterRole. Using the address of the nodes running the ingress controller pods", s.PublishService, err, ns)
+}
+
+// nodeAddresses returns the addresses of the node to publish in the status
+func (s *statusSync) nodeAddresses(node *apiv1.Node, addressTypes []apiv1.NodeAddressType) []string {
+	if s.NodeAddressAnnotation != "" {
+		if value := strings.TrimSpace(node.Annotations[s.NodeAddressAnnotation]); value != "" {
+			return splitAddresses(value)
+		}
+	}
+
+	if len(addressTypes) == 0 {
+		return []string{k8s.GetNodeAddress(node, s.UseNodeInternalIP)}
+	}
+
+	addrs := []string{}
+	for _, addressType := range addressTypes {
+		for _, address := range node.Status.Addresses {
+			if address.Type != addressType || address.Address == "" {
+				continue
+			}
+
+			if !stringInSlice(address.Address, addrs) {
+				addrs = append(addrs, address.Address)
+			}
+		}
+	}
+
+	return addrs
+}
+
+// isNodePublishable checks if the addresses of the node can be used in the
+// status, according to the node selector and the taints of the node
+func (s *statusSync) isNodePublishable(node *apiv1.Node) bool {
+	if s.NodeSelector != nil && !s.NodeSelector.Matches(labels.Set(node.Labels)) {
+		return false
+	}
+
+	if len(s.PublishZones) > 0 && !stringInSlice(node.Labels[apiv1.LabelTopologyZone], s.PublishZones) {
+		return false
+	}
+
+	if s.PublishTaintedNodes {
+		return true
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == apiv1.TaintEffectNoSchedule || taint.Effect == apiv1.TaintEffectNoExecute {
+			return false
+		}
+	}
+
+	return true
+}
+
+// watchPublishService triggers a sync of the status when the load balancer
+// status of the publish service changes
+func (s *statusSync) watchPublishService(stopCh chan struct{}) {
+	var infFactory informers.SharedInformerFactory
+	if s.PublishService != "" {
+		ns, name, err := k8s.ParseNameNS(s.PublishService)
+		if err != nil {
+			klog.ErrorS(err, "invalid publish service")
+			return
+		}
+
+		infFactory = informers.NewSharedInformerFactoryWithOptions(s.Client, 0,
+			informers.WithNamespace(ns),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+			}),
+		)
+	} else {
+		infFactory = informers.NewSharedInformerFactoryWithOptions(s.Client, 0,
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = s.PublishServiceSelector.String()
+			}),
+		)
+	}
+
+	informer := infFactory.Core().V1().Services().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			oldSvc, ok := old.(*apiv1.Service)
+			if !ok {
+				return
+			}
+
+			curSvc, ok := cur.(*apiv1.Service)
+			if !ok {
+				return
+			}
+
+			if !s.publishServiceChanged(oldSvc, curSvc) {
+				return
+			}
+
+			klog.InfoS("publish service load balancer status changed", "service", klog.KObj(curSvc))
+			s.enqueueAddressChange("publish service change")
+		},
+	})
+
+	go informer.Run(stopCh)
+}
+
+// usesNodeAddresses returns true if the published addresses are obtained
+// from the nodes running the ingress controller pods
+func (s *statusSync) usesNodeAddresses() bool {
+	return s.PublishStatusAddress == "" && s.PublishService == "" && s.PublishServiceSelector == nil
+}
+
+// watchNodes triggers a sync of the status when a node is removed, or
+// when the addresses of the node running this controller pod change,
+// without waiting for the next periodic sync.
+// The watched nodes are cached until stopCh is closed.
+func (s *statusSync) watchNodes(stopCh chan struct{}) {
+	nodeName := s.controllerNodeName()
+
+	infFactory := informers.NewSharedInformerFactory(s.Client, 0)
+	if s.SelectControllerNodes && nodeName != "" {
+		infFactory = informers.NewSharedInformerFactoryWithOptions(s.Client, 0,
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", nodeName).String()
+			}),
+		)
+	}
+
+	informer := infFactory.Core().V1().Nodes().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			s.nodeUpdated(nodeName, old.(*apiv1.Node), cur.(*apiv1.Node))
+		},
+		DeleteFunc: s.nodeDeleted,
+	})
+
+	s.nodes.set(informer.GetStore(), informer.HasSynced)
+	go func() {
+		<-stopCh
+		// the store is not updated after the informer stops
+		s.nodes.set(nil, nil)
+	}()
+
+	go informer.Run(stopCh)
+}
+
+// controllerNodeName returns the name of the node running this controller
+// pod, or an empty string if the pod cannot be read
+func (s *statusSync) controllerNodeName() string {
+	if k8s.IngressPodDetails == nil {
+		return ""
+	}
+
+	pod, err := s.Client.CoreV1().Pods(k8s.IngressPodDetails.Namespace).Get(context.TODO(), k8s.IngressPodDetails.Name, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("error obtaining the node of the ingress controller pod, the changes of its addresses are published in the next sync: %v", err)
+		return ""
+	}
+
+	return pod.Spec.NodeName
+}
+
+// nodeUpdated triggers a sync of the status when the published addresses of
+// the node running this controller pod change, like when the node is replaced
+func (s *statusSync) nodeUpdated(nodeName string, old, cur *apiv1.Node) {
+	if nodeName == "" || cur.Name != nodeName {
+		return
+	}
+
+	if reflect.DeepEqual(s.nodeAddresses(old, s.PublishAddressTypes), s.nodeAddresses(cur, s.PublishAddressTypes)) {
+		return
+	}
+
+	klog.InfoS("node addresses changed, updating the Ingress status", "node", cur.Name)
+	s.enqueueAddressChange("node updated")
+}
+
+func (s *statusSync) nodeDeleted(obj interface{}) {
+	node, ok := obj.(*apiv1.Node)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.ErrorS(nil, "Error obtaining object from tombstone", "key", obj)
+			return
+		}
+
+		node, ok = tombstone.Obj.(*apiv1.Node)
+		if !ok {
+			klog.Errorf("Tombstone contained object that is not a Node: %#v", obj)
+			return
+		}
+	}
+
+	klog.InfoS("node removed, updating the Ingress status", "node", node.Name)
+	s.enqueueAddressChange("node removed")
+}
+
+// publishServiceChanged checks if cur is the publish service and its load
+// balancer status is different from old
+func (s *statusSync) publishServiceChanged(old, cur *apiv1.Service) bool {
+	if s.PublishService != "" {
+		if fmt.Sprintf("%v/%v", cur.Namespace, cur.Name) != s.PublishService {
+			return false
+		}
+	} else if s.PublishServiceSelector == nil || !s.PublishServiceSelector.Matches(labels.Set(cur.Labels)) {
+		return false
+	}
+
+	return !reflect.DeepEqual(old.Status.LoadBalancer, cur.Status.LoadBalancer)
+}
+
+// otherReadyPods returns the number of Ready controller pods other than this
+// one, read from the API server instead of the informers
+func (s *statusSync) otherReadyPods() (int, error) {
+	pods, err := s.Client.CoreV1().Pods(k8s.IngressPodDetails.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(k8s.IngressPodDetails.Labels).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ready := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Name == k8s.IngressPodDetails.Name {
+			continue
+		}
+
+		if pod.Status.Phase == apiv1.PodRunning && isPodReady(pod) {
+			ready++
+		}
+	}
+
+	return ready, nil
+}
+
+// isPodReady returns true if the Ready condition of the pod is true
+func isPodReady(pod *apiv1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == apiv1.PodReady && cond.Status == apiv1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sliceToStatus converts a slice of IP and/or hostnames to LoadBalancerIngress.
+// The IP addresses, sorted numerically, are placed before the hostnames.
+// Hostnames are converted to lowercase and duplicated entries are removed.
+// IPv6 addresses with a zone identifier are skipped.
+func sliceToStatus(endpoints []string) []apiv1.LoadBalancerIngress {
+	lbi := []apiv1.LoadBalancerIngress{}
+	for _, ep := range endpoints {
+		if isZonedIP(ep) {
+			klog.V(2).InfoS("skipping IPv6 address with a zone identifier", "address", ep)
+			continue
+		}
+
+		entry := apiv1.LoadBalancerIngress{IP: ep}
+		if net.ParseIP(ep) == nil {
+			// hostnames are case insensitive
+			entry = apiv1.LoadBalancerIngress{Hostname: strings.ToLower(ep)}
+		}
+
+		if !hasLoadBalancerIngress(lbi, entry) {
+			lbi = append(lbi, entry)
+		}
+	}
+
+	sortLoadBalancerIngress(lbi)
+
+	return lbi
+}
+
+// updateStatus changes the status information of Ingress rules
+func (s *statusSync) updateStatus(ctx context.Context, newIngressPoint []apiv1.LoadBalancerIngress) syncResult {
+	ings := s.listIngresses()
+	result := s.updateIngresses(ctx, ings, newIngressPoint)
+
+	// remove the errors of Ingresses that no longer exist
+	keys := make(map[string]bool, len(ings))
+	for _, ing := range ings {
+		keys[fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)] = true
+	}
+	s.updateErrors.retain(keys)
+	s.conflicts.retain(keys)
+
+	return result
+}
+
+// updateIngresses changes the status of the given Ingresses
+func (s *statusSync) updateIngresses(ctx context.Context, ings []*ingress.Ingress, newIngressPoint []apiv1.LoadBalancerIngress) syncResult {
+	ctx, span := s.startSpan(ctx, "status.updateStatus")
+
+	result := syncResult{}
+	defer func() {
+		span.SetAttributes(resultAttributes(result)...)
+		span.End()
+	}()
+
+	p := pool.NewLimited(10)
+	defer p.Close()
+
+	batch := p.Batch()
+	s.sortStatus(newIngressPoint)
+	equal := s.statusEqualFunc()
+	writer := s.statusWriter()
+	typeStatus := addressTypeStatus{}
+	ports, hasPorts := s.publishServicePorts()
+	sourceRanges, hasSourceRanges := s.publishServiceSourceRanges()
+	index := newIngressIndex(s.listIngresses)
+	vips := s.newVIPPool(ctx)
+
+	// updates contains the update of each written Ingress
+	updates := map[string]statusUpdate{}
+	// writes contains the updates of the Ingresses, started once the status
+	// of all of them is computed
+	writes := []pool.WorkFunc{}
+
+	for _, ing := range ings {
+		key := fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)
+
+		if !s.isClaimed(ing) {
+			klog.V(3).InfoS("skipping update of Ingress (not claimed by this controller)", "namespace", ing.Namespace, "ingress", ing.Name)
+			s.updateErrors.clear(key)
+			result.skipped++
+			continue
+		}
+
+		ready, found := s.ingressReadiness(&ing.Ingress)
+		if !found {
+			klog.V(3).InfoS("skipping update of Ingress (not marked as ready)", "namespace", ing.Namespace, "ingress", ing.Name)
+			s.updateErrors.clear(key)
+			result.skipped++
+			continue
+		}
+
+		if len(newIngressPoint) == 0 && s.inEmptyAddressGrace(&ing.Ingress) {
+			klog.V(2).InfoS("skipping update of Ingress (empty address grace period)", "namespace", ing.Namespace, "ingress", ing.Name)
+			s.updateErrors.clear(key)
+			result.skipped++
+			continue
+		}
+
+		source, err := s.statusSource(ing, index)
+		if err != nil {
+			klog.Warningf("skipping update of Ingress %v: %v", key, err)
+			s.updateErrors.set(key, err)
+			result.failed++
+			continue
+		}
+
+		point, err := s.ingressAddresses(ctx, &source.Ingress, newIngressPoint, typeStatus, true)
+		if err != nil {
+			klog.ErrorS(err, "error obtaining the addresses of the Ingress", "namespace", ing.Namespace, "ingress", ing.Name)
+			s.updateErrors.set(key, err)
+			if isRetryableError(err) {
+				result.retryable++
+			}
+			result.failed++
+			continue
+		}
+
+		if vip, ok := s.ingressVIP(&source.Ingress, vips); ok {
+			point = vip
+		}
+
+		addrs := s.limitStatus(s.ingressStatus(&source.Ingress, point))
+		if !ready {
+			addrs = []apiv1.LoadBalancerIngress{}
+		}
+
+		curIPs := s.currentAddresses(&ing.Ingress)
+		s.sortStatus(curIPs)
+		desired := s.desiredStatus(&ing.Ingress, addrs)
+		statusChanged := !equal(curIPs, desired)
+
+		var annotations map[string]string
+		var removed []string
+		if hasPorts {
+			annotations, removed = statusPortsAnnotations(&ing.Ingress, ports)
+		}
+
+		if statusChanged && s.ControllerVersion != "" {
+			annotations = withControllerVersion(annotations, s.ControllerVersion)
+		}
+
+		if s.PublishHostStatus {
+			hostAnnotations, staleHosts := hostStatusAnnotations(&ing.Ingress, addrs)
+			annotations = mergeAnnotations(annotations, hostAnnotations)
+			removed = append(removed, staleHosts...)
+		}
+
+		if hasSourceRanges {
+			rangesAnnotations, staleRanges := sourceRangesAnnotations(&ing.Ingress, sourceRanges)
+			annotations = mergeAnnotations(annotations, rangesAnnotations)
+			removed = append(removed, staleRanges...)
+		}
+
+		proxyProtocol, staleProxyProtocol := proxyProtocolAnnotations(&ing.Ingress, addrs, s.PublishProxyProtocol)
+		annotations = mergeAnnotations(annotations, proxyProtocol)
+		removed = append(removed, staleProxyProtocol...)
+
+		if !statusChanged && len(annotations) == 0 && len(removed) == 0 && isGenerationObserved(&ing.Ingress) {
+			klog.V(3).InfoS("skipping update of Ingress (no change)", "namespace", ing.Namespace, "ingress", ing.Name)
+			s.updateErrors.clear(key)
+			result.skipped++
+			continue
+		}
+
+		updates[key] = statusUpdate{
+			ing:           ing,
+			previous:      curIPs,
+			written:       addrs,
+			desired:       desired,
+			statusChanged: statusChanged,
+		}
+		writes = append(writes, s.runUpdate(ctx, ing, addrs, annotations, removed, writer))
+	}
+
+	if isAtomicSync(ctx) && result.failed > 0 {
+		klog.Warningf("skipping update of the status of %v Ingresses (the status of %v Ingresses cannot be computed)", len(writes), result.failed)
+		writes = nil
+	}
+
+	for _, write := range writes {
+		batch.Queue(write)
+	}
+	batch.QueueComplete()
+
+	for wu := range batch.Results() {
+		key, ok := wu.Value().(string)
+		if apierrors.IsNotFound(wu.Error()) {
+			// the Ingress was deleted after it was listed
+			klog.V(2).InfoS("skipping update of Ingress (deleted during the sync)", "ingress", key)
+			if ok {
+				s.updateErrors.clear(key)
+			}
+			result.skipped++
+			continue
+		}
+
+		if wu.Error() != nil {
+			if ok {
+				s.updateErrors.set(key, wu.Error())
+			}
+
+			if isRetryableError(wu.Error()) {
+				result.retryable++
+			}
+
+			result.failed++
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		s.updateErrors.clear(key)
+		s.logSyncLatency(ctx, key)
+		result.updated++
+		result.changed = append(result.changed, key)
+
+		update := updates[key]
+		if s.OnStatusUpdated != nil {
+			s.OnStatusUpdated(key, update.written)
+		}
+
+		if s.ReconcileDone != nil {
+			select {
+			case s.ReconcileDone <- key:
+			default:
+				klog.V(2).InfoS("skipping notification of the Ingress update (ReconcileDone is full)", "ingress", key)
+			}
+		}
+
+		if update.statusChanged {
+			s.checkStatusConflict(&update.ing.Ingress, update.previous, update.desired)
+		}
+	}
+
+	sort.Strings(result.changed)
+
+	return result
+}
+
+// statusUpdate is the update of the status of an Ingress in a sync
+type statusUpdate struct {
+	ing *ingress.Ingress
+	// previous is the status replaced by the update
+	previous []apiv1.LoadBalancerIngress
+	// written is the status written by the update
+	written []apiv1.LoadBalancerIngress
+	// desired is the written status with the preserved addresses
+	desired []apiv1.LoadBalancerIngress
+	// statusChanged is false for the updates of the annotations only
+	statusChanged bool
+}
+
+func (s *statusSync) runUpdate(ctx context.Context, ing *ingress.Ingress, status []apiv1.LoadBalancerIngress,
+	annotations map[string]string, removed []string, writer StatusWriter) pool.WorkFunc {
+	return func(wu pool.WorkUnit) (interface{}, error) {
+		if wu.IsCancelled() {
+			return nil, nil
+		}
+
+		key := fmt.Sprintf("%v/%v", ing.Namespace, ing.Name)
+
+		_, span := s.startSpan(ctx, "status.write",
+			attribute.String("ingress.namespace", ing.Namespace),
+			attribute.String("ingress.name", ing.Name))
+		err := writer.Write(ing, status)
+		if err == nil {
+			ingClient := s.Client.NetworkingV1beta1().Ingresses(ing.Namespace)
+			err = patchAnnotations(ingClient, &ing.Ingress, annotations, removed, s.APICallTimeout)
+		}
+		endSpan(span, err)
+		if err != nil {
+			return key, err
+		}
+
+		s.recordStatusEvent(&ing.Ingress, status)
+
+		return key, nil
+	}
+}
+
+// desiredStatus returns the addresses the Ingress should contain,
+// including the preserved entries not written by the controller
+func (s *statusSync) desiredStatus(ing *networking.Ingress, addrs []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	if !s.PreserveUnknownStatusEntries || s.StatusToAnnotation {
+		return addrs
+	}
+
+	desired := preserveUnknownEntries(ing, addrs)
+	s.sortStatus(desired)
+
+	return desired
+}
+
+// currentAddresses returns a copy of the addresses published in the Ingress,
+// so it can be sorted without modifying the Ingress returned by the lister
+func (s *statusSync) currentAddresses(ing *networking.Ingress) []apiv1.LoadBalancerIngress {
+	if s.StatusToAnnotation {
+		return statusFromAnnotation(ing)
+	}
+
+	return append([]apiv1.LoadBalancerIngress{}, ing.Status.LoadBalancer.Ingress...)
+}
+
+// statusWriter returns the configured StatusWriter or the default Ingress writer
+func (s *statusSync) statusWriter() StatusWriter {
+	if s.StatusWriter != nil {
+		return s.StatusWriter
+	}
+
+	if s.StatusToAnnotation {
+		return &ingressAnnotationWriter{
+			client:  s.Client,
+			timeout: s.APICallTimeout,
+		}
+	}
+
+	return &ingressStatusWriter{
+		client:          s.Client,
+		timeout:         s.APICallTimeout,
+		preserveUnknown: s.PreserveUnknownStatusEntries,
+		sort:            s.sortStatus,
+		mergePatch:      s.UseMergePatch,
+	}
+}
+
+// sortStatus sorts the addresses in the canonical order and then moves
+// the ones matching the configured AddressPriority to the front
+func (s *statusSync) sortStatus(addrs []apiv1.LoadBalancerIngress) {
+	sortLoadBalancerIngress(addrs)
+	if len(s.AddressPriority) == 0 {
+		return
+	}
+
+	sort.SliceStable(addrs, func(a, b int) bool {
+		return addressPriorityRank(addrs[a], s.AddressPriority) < addressPriorityRank(addrs[b], s.AddressPriority)
+	})
+}
+
+// limitStatus returns the first MaxPublishedAddresses of the sorted addresses
+func (s *statusSync) limitStatus(addrs []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	if s.MaxPublishedAddresses <= 0 || len(addrs) <= s.MaxPublishedAddresses {
+		return addrs
+	}
+
+	return addrs[:s.MaxPublishedAddresses]
+}
+
+// addressPriorityRank returns the index of the first entry in priority
+// matching the address, or len(priority) if none of them match
+func addressPriorityRank(addr apiv1.LoadBalancerIngress, priority []string) int {
+	for i, p := range priority {
+		if addressMatches(addr, p) {
+			return i
+		}
+	}
+
+	return len(priority)
+}
+
+// addressMatches checks if the IP or hostname of the address is contained
+// in the CIDR or matches the glob pattern
+func addressMatches(addr apiv1.LoadBalancerIngress, pattern string) bool {
+	if _, cidr, err := net.ParseCIDR(pattern); err == nil {
+		ip := net.ParseIP(addr.IP)
+		return ip != nil && cidr.Contains(ip)
+	}
+
+	for _, v := range []string{addr.IP, addr.Hostname} {
+		if v == "" {
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortLoadBalancerIngress sorts the addresses in the canonical order used
+// to publish and compare the status: IP addresses, sorted numerically,
+// before hostnames, sorted alphabetically
+func sortLoadBalancerIngress(addrs []apiv1.LoadBalancerIngress) {
+	sort.SliceStable(addrs, lessLoadBalancerIngress(addrs))
+}
+
+// isAddressExcluded checks if the address is an IP contained in ExcludeCIDRs
+func (s *statusSync) isAddressExcluded(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range s.excludeCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseCIDRs parses a list of CIDRs, returning an error for the first invalid one
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	result := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, cidr, err := net.ParseCIDR(strings.TrimSpace(c))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q in the excluded addresses: %v", c, err)
+		}
+
+		result = append(result, cidr)
+	}
+
+	return result, nil
+}
+
+func lessLoadBalancerIngress(addrs []apiv1.LoadBalancerIngress) func(int, int) bool {
+	return func(a, b int) bool {
+		switch strings.Compare(addrs[a].Hostname, addrs[b].Hostname) {
+		case -1:
+			return true
+		case 1:
+			return false
+		}
+		return compareIPs(addrs[a].IP, addrs[b].IP) < 0
+	}
+}
+
+// compareIPs compares two IP addresses numerically. IPv4 addresses are
+// sorted before IPv6 ones. Invalid addresses are compared as strings.
+func compareIPs(a, b string) int {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return strings.Compare(a, b)
+	}
+
+	return bytes.Compare(ipA.To16(), ipB.To16())
+}
+
+// ingressSliceEqual compares the addresses in order. Both slices must be
+// sorted with sortLoadBalancerIngress
+func ingressSliceEqual(lhs, rhs []apiv1.LoadBalancerIngress) bool {
+	if len(lhs) != len(rhs) {
+		return false
+	}
+
+	added, removed := diffLoadBalancerIngress(lhs, rhs)
+	if len(added) > 0 || len(removed) > 0 {
+		return false
+	}
+
+	// the diff ignores the order and the duplicated addresses
+	for i := range lhs {
+		if !loadBalancerIngressEqual(lhs[i], rhs[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffLoadBalancerIngress returns the addresses of cur not present in old,
+// and the addresses of old not present in cur. The order is ignored.
+func diffLoadBalancerIngress(old, cur []apiv1.LoadBalancerIngress) (added, removed []apiv1.LoadBalancerIngress) {
+	for _, addr := range cur {
+		if !hasLoadBalancerIngress(old, addr) && !hasLoadBalancerIngress(added, addr) {
+			added = append(added, addr)
+		}
+	}
+
+	for _, addr := range old {
+		if !hasLoadBalancerIngress(cur, addr) && !hasLoadBalancerIngress(removed, addr) {
+			removed = append(removed, addr)
+		}
+	}
+
+	return added, removed
+}
+
+// loadBalancerIngressEqual compares the IP and the hostname, ignoring case
+func loadBalancerIngressEqual(lhs, rhs apiv1.LoadBalancerIngress) bool {
+	return lhs.IP == rhs.IP && strings.EqualFold(lhs.Hostname, rhs.Hostname)
+}
+
+// hasLoadBalancerIngress returns true if addrs contains an entry with the
+// IP and the hostname of entry. Hostnames are compared case insensitively.
+func hasLoadBalancerIngress(addrs []apiv1.LoadBalancerIngress, entry apiv1.LoadBalancerIngress) bool {
+	for _, addr := range addrs {
+		if loadBalancerIngressEqual(addr, entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizedIngressSliceEqual compares the addresses after normalizing them
+func normalizedIngressSliceEqual(lhs, rhs []apiv1.LoadBalancerIngress) bool {
+	return ingressSliceEqual(normalizeLoadBalancerIngress(lhs), normalizeLoadBalancerIngress(rhs))
+}
+
+// normalizeLoadBalancerIngress returns a canonical copy of the addresses.
+// The spaces around the values are removed, hostnames containing an IP
+// address are converted to IP entries, entries without IP and hostname
+// are removed, and duplicated entries are merged. The result is sorted.
+func normalizeLoadBalancerIngress(addrs []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	result := []apiv1.LoadBalancerIngress{}
+	for _, addr := range addrs {
+		entry := apiv1.LoadBalancerIngress{
+			IP:       strings.TrimSpace(addr.IP),
+			Hostname: strings.ToLower(strings.TrimSpace(addr.Hostname)),
+			Ports:    addr.Ports,
+		}
+
+		if entry.IP == "" && net.ParseIP(entry.Hostname) != nil {
+			entry.IP, entry.Hostname = entry.Hostname, ""
+		}
+
+		if entry.IP == "" && entry.Hostname == "" {
+			continue
+		}
+
+		if !hasLoadBalancerIngress(result, entry) {
+			result = append(result, entry)
+		}
+	}
+
+	sortLoadBalancerIngress(result)
+
+	return result
+}
+
+// statusEqualFunc returns the function used to compare the current and the
+// new status of the Ingresses. Resolved hostnames are cached by the function.
+func (s *statusSync) statusEqualFunc() func(lhs, rhs []apiv1.LoadBalancerIngress) bool {
+	sliceEqual := ingressSliceEqual
+	if s.NormalizeStatusEntries {
+		sliceEqual = normalizedIngressSliceEqual
+	}
+
+	if !s.ResolveHostnamesForEquality || s.lookupHost == nil {
+		return sliceEqual
+	}
+
+	resolved := map[string][]string{}
+	lookup := func(host string) []string {
+		if ips, ok := resolved[host]; ok {
+			return ips
+		}
+
+		ips, err := s.lookupHost(host)
+		if err != nil || len(ips) == 0 {
+			klog.V(3).InfoS("unable to resolve hostname, comparing it literally", "hostname", host, "err", err)
+			ips = []string{host}
+		}
+
+		resolved[host] = ips
+		return ips
+	}
+
+	return func(lhs, rhs []apiv1.LoadBalancerIngress) bool {
+		if sliceEqual(lhs, rhs) {
+			return true
+		}
+
+		return resolvedEndpoints(lhs, lookup).Equal(resolvedEndpoints(rhs, lookup))
+	}
+}
+
+// resolvedEndpoints returns the IP addresses of the status, resolving the
+// entries that only contain a hostname
+func resolvedEndpoints(addrs []apiv1.LoadBalancerIngress, lookup func(string) []string) sets.String {
+	endpoints := sets.NewString()
+	for _, addr := range addrs {
+		if addr.IP != "" {
+			endpoints.Insert(addr.IP)
+			continue
+		}
+
+		endpoints.Insert(lookup(addr.Hostname)...)
+	}
+
+	return endpoints
+}
+
+func statusAddressFromService(service string, kubeClient clientset.Interface, includeClusterIP bool) ([]string, error) {
+	ns, name, _ := k8s.ParseNameNS(service)
+	svc, err := kubeClient.CoreV1().Services(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceAddresses(svc, includeClusterIP)
+}
+
+// publishServiceAddresses returns the addresses of the publish service,
+// or of its ready endpoints with PublishReadyEndpoints
+func (s *statusSync) publishServiceAddresses(service string) ([]string, error) {
+	if s.PublishReadyEndpoints {
+		return statusAddressFromEndpoints(service, s.Client)
+	}
+
+	return statusAddressFromService(service, s.Client, s.PublishClusterIP)
+}
+
+// publishServiceBySelector returns the only service matching PublishServiceSelector
+func (s *statusSync) publishServiceBySelector() (*apiv1.Service, error) {
+	svcs, err := s.Client.CoreV1().Services(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: s.PublishServiceSelector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(svcs.Items) {
+	case 0:
+		return nil, fmt.Errorf("no publish service matches the selector %q", s.PublishServiceSelector)
+	case 1:
+		return &svcs.Items[0], nil
+	}
+
+	names := make([]string, 0, len(svcs.Items))
+	for _, svc := range svcs.Items {
+		names = append(names, fmt.Sprintf("%v/%v", svc.Namespace, svc.Name))
+	}
+	sort.Strings(names)
+
+	return nil, fmt.Errorf("multiple publish services match the selector %q: %v", s.PublishServiceSelector, strings.Join(names, ", "))
+}
+
+// serviceAddresses returns the addresses of the service to publish in the
+// status, and its cluster IP if includeClusterIP is set
+func serviceAddresses(svc *apiv1.Service, includeClusterIP bool) ([]string, error) {
+	addrs, err := serviceTypeAddresses(svc)
+	if err != nil || !includeClusterIP || !hasClusterIP(svc) {
+		return addrs, err
+	}
+
+	if !stringInSlice(svc.Spec.ClusterIP, addrs) {
+		addrs = append(addrs, svc.Spec.ClusterIP)
+	}
+
+	return addrs, nil
+}
+
+// hasClusterIP returns true if the service has a cluster IP, unlike the
+// headless services
+func hasClusterIP(svc *apiv1.Service) bool {
+	return svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != apiv1.ClusterIPNone
+}
+
+// serviceTypeAddresses returns the addresses of the service depending on its type
+func serviceTypeAddresses(svc *apiv1.Service) ([]string, error) {
+	switch svc.Spec.Type {
+	case apiv1.ServiceTypeExternalName:
+		return []string{svc.Spec.ExternalName}, nil
+	case apiv1.ServiceTypeClusterIP:
+		return []string{svc.Spec.ClusterIP}, nil
+	case apiv1.ServiceTypeNodePort:
+		addresses := sets.NewString()
+		if svc.Spec.ExternalIPs != nil {
+			addresses.Insert(svc.Spec.ExternalIPs...)
+		} else {
+			addresses.Insert(svc.Spec.ClusterIP)
+		}
+		return addresses.List(), nil
+	case apiv1.ServiceTypeLoadBalancer:
+		addresses := sets.NewString()
+		for _, ip := range svc.Status.LoadBalancer.Ingress {
+			if ip.IP == "" {
 				addresses.Insert(ip.Hostname)
 			} else {
 				addresses.Insert(ip.IP)
@@ -356,7 +2057,13 @@ func statusAddressFromService(service string, kubeClient clientset.Interface) ([
 		return addresses.List(), nil
 	}
 
-	return nil, fmt.Errorf("unable to extract IP address/es from service %v", service)
+	return nil, fmt.Errorf("unable to extract IP address/es from service %v/%v", svc.Namespace, svc.Name)
+}
+
+// splitAddresses returns the addresses in a comma separated list
+func splitAddresses(value string) []string {
+	re := regexp.MustCompile(`,\s*`)
+	return re.Split(value, -1)
 }
 
 // stringInSlice returns true if s is in list
@@ -18,18 +18,33 @@ package status
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	apiv1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
 	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"k8s.io/ingress-nginx/internal/ingress"
 	"k8s.io/ingress-nginx/internal/ingress/annotations/class"
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
 	"k8s.io/ingress-nginx/internal/k8s"
 	"k8s.io/ingress-nginx/internal/task"
 )
@@ -161,6 +176,9 @@ func buildSimpleClientSet() *testclient.Clientset {
 			{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "foo_node_2",
+					Annotations: map[string]string{
+						"example.com/public-address": "12.0.0.10, 12.0.0.11",
+					},
 				},
 				Status: apiv1.NodeStatus{
 					Addresses: []apiv1.NodeAddress{
@@ -270,13 +288,36 @@ func (til *testIngressLister) ListIngresses() []*ingress.Ingress {
 	return ingresses
 }
 
+// clientIngressLister lists the Ingresses stored in the API server
+type clientIngressLister struct {
+	client clientset.Interface
+}
+
+func (cil *clientIngressLister) ListIngresses() []*ingress.Ingress {
+	list, err := cil.client.NetworkingV1beta1().Ingresses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	var ingresses []*ingress.Ingress
+	for _, ing := range list.Items {
+		ingresses = append(ingresses, &ingress.Ingress{Ingress: ing})
+	}
+
+	return ingresses
+}
+
 func buildIngressLister() ingressLister {
 	return &testIngressLister{}
 }
 
 func buildStatusSync() statusSync {
 	return statusSync{
-		syncQueue: task.NewTaskQueue(fakeSynFn),
+		syncQueue:     task.NewTaskQueue(fakeSynFn),
+		updateErrors:  newUpdateErrors(clock.RealClock{}),
+		lastStatus:    &statusCache{},
+		syncLock:      &sync.RWMutex{},
+		reconcileLock: &sync.Mutex{},
 		Config: Config{
 			Client:         buildSimpleClientSet(),
 			PublishService: apiv1.NamespaceDefault + "/" + "foo",
@@ -296,9 +337,10 @@ func TestStatusActions(t *testing.T) {
 		UpdateStatusOnShutdown: true,
 	}
 
+	// the syncer runs in foo1, the only controller pod
 	k8s.IngressPodDetails = &k8s.PodInfo{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "foo_base_pod",
+			Name:      "foo1",
 			Namespace: apiv1.NamespaceDefault,
 			Labels: map[string]string{
 				"label_sig": "foo_pod",
@@ -307,22 +349,21 @@ func TestStatusActions(t *testing.T) {
 	}
 
 	// create object
-	fkSync := NewStatusSyncer(c)
+	fkSync, err := NewStatusSyncer(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if fkSync == nil {
 		t.Fatalf("expected a valid Sync")
 	}
 
-	fk := fkSync.(statusSync)
-
-	// start it and wait for the election and syn actions
-	stopCh := make(chan struct{})
-	defer close(stopCh)
+	fk := fkSync.(*statusSync)
 
-	go fk.Run(stopCh)
-	//  wait for the election
-	time.Sleep(100 * time.Millisecond)
-	// execute sync
-	fk.sync("just-test")
+	// sync as the leader, without an election
+	fk.setLeading(true)
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	// PublishService is empty, so the running address is: ["11.0.0.2"]
 	// after updated, the ingress's ip should only be "11.0.0.2"
 	newIPs := []apiv1.LoadBalancerIngress{{
@@ -337,8 +378,6 @@ func TestStatusActions(t *testing.T) {
 		t.Fatalf("returned %v but expected %v", fooIngress1CurIPs, newIPs)
 	}
 
-	time.Sleep(1 * time.Second)
-
 	// execute shutdown
 	fk.Shutdown()
 	// ingress should be empty
@@ -361,6 +400,355 @@ func TestStatusActions(t *testing.T) {
 	}
 }
 
+func TestReconcileResult(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "11.0.0.2"
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// foo_ingress_non_01 does not exist in the API server
+	expected := syncResult{
+		updated: 1,
+		skipped: 1,
+		failed:  0,
+		changed: []string{"default/foo_ingress_1"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("returned %+v but expected %+v", r, expected)
+	}
+
+	// foo_ingress_non_01 has no addresses but the generation was not recorded
+	r = fk.updateStatus(context.TODO(), []apiv1.LoadBalancerIngress{})
+	expected = syncResult{
+		updated: 1,
+		skipped: 1,
+		failed:  0,
+		changed: []string{"default/foo_ingress_1"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("returned %+v but expected %+v", r, expected)
+	}
+}
+
+func TestObservedGeneration(t *testing.T) {
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo_ingress",
+			Namespace:  apiv1.NamespaceDefault,
+			Generation: 3,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+
+	key := parser.GetAnnotationWithPrefix(observedGenerationAnnotation)
+	checkGeneration := func(expected string) {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ing.Annotations[key] != expected {
+			t.Errorf("returned generation %v but expected %v", ing.Annotations[key], expected)
+		}
+	}
+
+	r, _ := fk.reconcile(context.TODO())
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+	checkGeneration("3")
+
+	r, _ = fk.reconcile(context.TODO())
+	if r.skipped != 1 {
+		t.Errorf("expected one skipped Ingress but returned %+v", r)
+	}
+
+	ing, _ := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	ing.Generation = 4
+	_, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Update(context.TODO(), ing, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, _ = fk.reconcile(context.TODO())
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+	checkGeneration("4")
+}
+
+func TestPauseAndResume(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+
+	countWrites := func() int {
+		writes := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "update" || action.GetVerb() == "patch" {
+				writes++
+			}
+		}
+		return writes
+	}
+
+	fk.Pause()
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if writes := countWrites(); writes != 0 {
+		t.Errorf("expected no writes while paused but %v were made", writes)
+	}
+
+	fk.Resume()
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if writes := countWrites(); writes == 0 {
+		t.Errorf("expected writes after resume")
+	}
+}
+
+func TestStatusEqualWithResolvedHostnames(t *testing.T) {
+	fk := buildStatusSync()
+	fk.ResolveHostnamesForEquality = true
+	fk.lookupHost = func(host string) ([]string, error) {
+		switch host {
+		case "lb.example.com":
+			return []string{"10.0.0.1"}, nil
+		case "multi.example.com":
+			return []string{"10.0.0.1", "10.0.0.2"}, nil
+		}
+		return nil, fmt.Errorf("host %v not found", host)
+	}
+
+	fooTests := []struct {
+		title string
+		lhs   []apiv1.LoadBalancerIngress
+		rhs   []apiv1.LoadBalancerIngress
+		er    bool
+	}{
+		{"hostname resolves to IP", []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}, []apiv1.LoadBalancerIngress{{Hostname: "lb.example.com"}}, true},
+		{"hostname resolves to another IP", []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}, []apiv1.LoadBalancerIngress{{Hostname: "lb.example.com"}}, false},
+		{"hostname resolves to several IPs", []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}, []apiv1.LoadBalancerIngress{{Hostname: "multi.example.com"}}, true},
+		{"unresolvable hostname", []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}, []apiv1.LoadBalancerIngress{{Hostname: "unknown.example.com"}}, false},
+		{"same unresolvable hostname", []apiv1.LoadBalancerIngress{{Hostname: "unknown.example.com"}}, []apiv1.LoadBalancerIngress{{Hostname: "unknown.example.com"}}, true},
+	}
+
+	equal := fk.statusEqualFunc()
+	for _, fooTest := range fooTests {
+		r := equal(fooTest.lhs, fooTest.rhs)
+		if r != fooTest.er {
+			t.Errorf("%v: returned %v but expected %v", fooTest.title, r, fooTest.er)
+		}
+	}
+
+	fk.ResolveHostnamesForEquality = false
+	equal = fk.statusEqualFunc()
+	if equal([]apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}, []apiv1.LoadBalancerIngress{{Hostname: "lb.example.com"}}) {
+		t.Errorf("expected literal comparison when hostname resolution is disabled")
+	}
+}
+
+func TestPublishServiceChange(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		&apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: apiv1.NamespaceDefault,
+			},
+			Spec: apiv1.ServiceSpec{
+				Type: apiv1.ServiceTypeLoadBalancer,
+			},
+			Status: apiv1.ServiceStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+				},
+			},
+		},
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_ingress",
+				Namespace: apiv1.NamespaceDefault,
+			},
+		},
+	)
+
+	syncer, err := NewStatusSyncer(Config{
+		Client:            client,
+		PublishService:    apiv1.NamespaceDefault + "/" + "foo",
+		IngressLister:     &clientIngressLister{client},
+		MetricsRegisterer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go fk.Run(stopCh)
+
+	waitForStatus := func(ip string) {
+		err := wait.Poll(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+			ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			lbi := ing.Status.LoadBalancer.Ingress
+			return len(lbi) == 1 && lbi[0].IP == ip, nil
+		})
+		if err != nil {
+			t.Fatalf("expected Ingress status with IP %v: %v", ip, err)
+		}
+	}
+
+	waitForStatus("10.0.0.1")
+
+	svc, err := client.CoreV1().Services(apiv1.NamespaceDefault).Get(context.TODO(), "foo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: "10.0.0.9"}}
+	_, err = client.CoreV1().Services(apiv1.NamespaceDefault).UpdateStatus(context.TODO(), svc, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitForStatus("10.0.0.9")
+}
+
+func TestPublishServiceChanged(t *testing.T) {
+	fk := buildStatusSync()
+
+	svc := func(name, ip string) *apiv1.Service {
+		return &apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: apiv1.NamespaceDefault,
+			},
+			Status: apiv1.ServiceStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: ip}},
+				},
+			},
+		}
+	}
+
+	if !fk.publishServiceChanged(svc("foo", "10.0.0.1"), svc("foo", "10.0.0.2")) {
+		t.Errorf("expected a change in the publish service")
+	}
+	if fk.publishServiceChanged(svc("foo", "10.0.0.1"), svc("foo", "10.0.0.1")) {
+		t.Errorf("expected no change in the publish service")
+	}
+	if fk.publishServiceChanged(svc("bar", "10.0.0.1"), svc("bar", "10.0.0.2")) {
+		t.Errorf("expected changes in other services to be ignored")
+	}
+}
+
+func TestConcurrentSync(t *testing.T) {
+	syncer, err := NewStatusSyncer(Config{
+		Client:               buildSimpleClientSet(),
+		PublishStatusAddress: "10.0.0.1,10.0.0.2",
+		IngressLister:        buildIngressLister(),
+		AddressDrainPeriod:   time.Minute,
+		SyncWorkers:          4,
+		MetricsRegisterer:    prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fk.sync("just-test"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
+
+// concurrencyResolver records the max number of concurrent resolutions
+type concurrencyResolver struct {
+	running, max int32
+}
+
+func (r *concurrencyResolver) Resolve(ctx context.Context) ([]string, error) {
+	cur := atomic.AddInt32(&r.running, 1)
+	defer atomic.AddInt32(&r.running, -1)
+	for {
+		max := atomic.LoadInt32(&r.max)
+		if cur <= max || atomic.CompareAndSwapInt32(&r.max, max, cur) {
+			break
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	return nil, nil
+}
+
+func TestConcurrentSyncSerialized(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	// the resolver is called once by each reconciliation
+	resolver := &concurrencyResolver{}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.AddressResolvers = []AddressResolver{resolver}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fk.sync(task.Element{Key: task.GetDummyObject("sync status")}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if m := atomic.LoadInt32(&resolver.max); m != 1 {
+		t.Errorf("expected the reconciliations to run one at a time, but %d ran at the same time", m)
+	}
+}
+
 func TestCallback(t *testing.T) {
 	buildStatusSync()
 }
@@ -559,6 +947,55 @@ func TestRunningAddressesWithPublishService(t *testing.T) {
 	}
 }
 
+func TestRunningAddressesWithPublishClusterIP(t *testing.T) {
+	service := func(clusterIP string) *apiv1.Service {
+		return &apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: apiv1.NamespaceDefault,
+			},
+			Spec: apiv1.ServiceSpec{
+				Type:      apiv1.ServiceTypeLoadBalancer,
+				ClusterIP: clusterIP,
+			},
+			Status: apiv1.ServiceStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		clusterIP        string
+		publishClusterIP bool
+		expected         []string
+	}{
+		{"disabled", "10.96.0.10", false, []string{"10.0.0.1"}},
+		{"enabled", "10.96.0.10", true, []string{"10.0.0.1", "10.96.0.10"}},
+		{"headless", apiv1.ClusterIPNone, true, []string{"10.0.0.1"}},
+		{"without cluster IP", "", true, []string{"10.0.0.1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.Client = testclient.NewSimpleClientset(service(tc.clusterIP))
+			fk.PublishClusterIP = tc.publishClusterIP
+
+			ra, err := fk.runningAddresses()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(ra, tc.expected) {
+				t.Errorf("returned %v but expected %v", ra, tc.expected)
+			}
+		})
+	}
+}
+
 func TestRunningAddressesWithPods(t *testing.T) {
 	fk := buildStatusSync()
 	fk.PublishService = ""
@@ -577,6 +1014,109 @@ func TestRunningAddressesWithPods(t *testing.T) {
 	}
 }
 
+func TestRunningAddressesWithPublishAddressTypes(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishAddressTypes = []apiv1.NodeAddressType{apiv1.NodeExternalIP, apiv1.NodeInternalIP}
+
+	ra, err := fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error obtaining running address/es: %v", err)
+	}
+
+	expected := []string{"11.0.0.2", "11.0.0.1"}
+	if !reflect.DeepEqual(expected, ra) {
+		t.Errorf("returned %v but expected %v", ra, expected)
+	}
+
+	fk.PublishAddressTypes = []apiv1.NodeAddressType{apiv1.NodeInternalIP}
+
+	ra, err = fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error obtaining running address/es: %v", err)
+	}
+
+	expected = []string{"11.0.0.1"}
+	if !reflect.DeepEqual(expected, ra) {
+		t.Errorf("returned %v but expected %v", ra, expected)
+	}
+}
+
+func TestRunningAddressesWithNodeAddressAnnotation(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.NodeAddressAnnotation = "example.com/public-address"
+
+	ra, err := fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error obtaining running address/es: %v", err)
+	}
+
+	expected := []string{"12.0.0.10", "12.0.0.11"}
+	if !reflect.DeepEqual(expected, ra) {
+		t.Errorf("returned %v but expected %v", ra, expected)
+	}
+
+	fk.NodeAddressAnnotation = "example.com/missing-address"
+
+	ra, err = fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error obtaining running address/es: %v", err)
+	}
+
+	expected = []string{"11.0.0.2"}
+	if !reflect.DeepEqual(expected, ra) {
+		t.Errorf("returned %v but expected %v", ra, expected)
+	}
+}
+
+func TestRunningAddressesWithMissingPublishService(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = apiv1.NamespaceDefault + "/" + "foo_missing"
+
+	ra, err := fk.runningAddresses()
+	if err != nil {
+		t.Fatalf("unexpected error obtaining running address/es: %v", err)
+	}
+
+	expected := []string{"11.0.0.2"}
+	if !reflect.DeepEqual(expected, ra) {
+		t.Errorf("returned %v but expected %v", ra, expected)
+	}
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Errorf("unexpected error syncing status: %v", err)
+	}
+}
+
+func TestRunningAddressesWithForbiddenPublishService(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = "other/foo"
+	fk.Client.(*testclient.Clientset).PrependReactor("get", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "foo", fmt.Errorf("denied"))
+	})
+
+	for i := 0; i < 2; i++ {
+		ra, err := fk.runningAddresses()
+		if err != nil {
+			t.Fatalf("unexpected error obtaining running address/es: %v", err)
+		}
+
+		expected := []string{"11.0.0.2"}
+		if !reflect.DeepEqual(expected, ra) {
+			t.Errorf("returned %v but expected %v", ra, expected)
+		}
+	}
+
+	if atomic.LoadInt32(&fk.publishServiceForbidden) != 1 {
+		t.Errorf("expected the missing permission to be reported")
+	}
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Errorf("unexpected error syncing status: %v", err)
+	}
+}
+
 func TestRunningAddressesWithPublishStatusAddress(t *testing.T) {
 	fk := buildStatusSync()
 	fk.PublishStatusAddress = "127.0.0.1"
@@ -656,21 +1196,84 @@ func TestSliceToStatus(t *testing.T) {
 		t.Fatalf("returned %v but expected %v", rl, 3)
 	}
 	re1 := r[0]
-	if re1.Hostname != "opensource-k8s-ingress" {
-		t.Fatalf("returned %v but expected %v", re1, apiv1.LoadBalancerIngress{Hostname: "opensource-k8s-ingress"})
+	if re1.IP != "10.0.0.1" {
+		t.Fatalf("returned %v but expected %v", re1, apiv1.LoadBalancerIngress{IP: "10.0.0.1"})
 	}
 	re2 := r[1]
-	if re2.IP != "10.0.0.1" {
-		t.Fatalf("returned %v but expected %v", re2, apiv1.LoadBalancerIngress{IP: "10.0.0.1"})
+	if re2.IP != "2001:db8::68" {
+		t.Fatalf("returned %v but expected %v", re2, apiv1.LoadBalancerIngress{IP: "2001:db8::68"})
 	}
 	re3 := r[2]
-	if re3.IP != "2001:db8::68" {
-		t.Fatalf("returned %v but expected %v", re3, apiv1.LoadBalancerIngress{IP: "2001:db8::68"})
+	if re3.Hostname != "opensource-k8s-ingress" {
+		t.Fatalf("returned %v but expected %v", re3, apiv1.LoadBalancerIngress{Hostname: "opensource-k8s-ingress"})
+	}
+
+	// hostnames are converted to lowercase and deduplicated
+	r = sliceToStatus([]string{"LB.Example.com", "10.0.0.1", "lb.example.COM", "Opensource-K8s-Ingress"})
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.1"},
+		{Hostname: "lb.example.com"},
+		{Hostname: "opensource-k8s-ingress"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("returned %v but expected %v", r, expected)
 	}
 }
 
-func TestIngressSliceEqual(t *testing.T) {
-	fk1 := buildLoadBalancerIngressByIP()
+func TestSliceToStatusShuffled(t *testing.T) {
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.2"},
+		{IP: "10.0.0.10"},
+		{IP: "2001:db8::68"},
+		{Hostname: "a.example.com"},
+		{Hostname: "b.example.com"},
+	}
+
+	fooTests := [][]string{
+		{"10.0.0.2", "10.0.0.10", "2001:db8::68", "a.example.com", "b.example.com"},
+		{"b.example.com", "2001:db8::68", "10.0.0.10", "a.example.com", "10.0.0.2"},
+		{"a.example.com", "10.0.0.10", "b.example.com", "10.0.0.2", "2001:db8::68"},
+	}
+
+	for _, fooTest := range fooTests {
+		r := sliceToStatus(fooTest)
+		if !reflect.DeepEqual(r, expected) {
+			t.Errorf("returned %v but expected %v", r, expected)
+		}
+	}
+}
+
+func TestSortLoadBalancerIngress(t *testing.T) {
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.2"},
+		{IP: "10.0.0.10"},
+		{IP: "2001:db8::68"},
+		{Hostname: "a.example.com"},
+		{Hostname: "b.example.com"},
+	}
+
+	addrs := []apiv1.LoadBalancerIngress{
+		{Hostname: "b.example.com"},
+		{IP: "2001:db8::68"},
+		{IP: "10.0.0.10"},
+		{Hostname: "a.example.com"},
+		{IP: "10.0.0.2"},
+	}
+
+	sortLoadBalancerIngress(addrs)
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("returned %v but expected %v", addrs, expected)
+	}
+
+	// the published status and the sorted current status must be equal
+	published := sliceToStatus([]string{"a.example.com", "10.0.0.10", "b.example.com", "10.0.0.2", "2001:db8::68"})
+	if !ingressSliceEqual(published, addrs) {
+		t.Errorf("expected %v to be equal to %v", published, addrs)
+	}
+}
+
+func TestIngressSliceEqual(t *testing.T) {
+	fk1 := buildLoadBalancerIngressByIP()
 	fk2 := append(buildLoadBalancerIngressByIP(), apiv1.LoadBalancerIngress{
 		IP:       "10.0.0.5",
 		Hostname: "foo5",
@@ -680,24 +1283,1049 @@ func TestIngressSliceEqual(t *testing.T) {
 	fk4 := buildLoadBalancerIngressByIP()
 	fk4[2].IP = "11.0.0.3"
 
+	// the same addresses with empty field artifacts
+	fk5 := append(buildLoadBalancerIngressByIP(), apiv1.LoadBalancerIngress{})
+	fk6 := buildLoadBalancerIngressByIP()
+	fk6[2].IP = " 10.0.0.3 "
+	fk6 = append(fk6, fk6[0])
+	fk7 := []apiv1.LoadBalancerIngress{{Hostname: "10.0.0.1"}}
+	fk8 := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1", Hostname: ""}}
+
+	// hostnames are case insensitive
+	fk9 := buildLoadBalancerIngressByIP()
+	fk9[0].Hostname = "FOO1"
+	fk10 := []apiv1.LoadBalancerIngress{{Hostname: "LB.Example.com"}, {Hostname: "lb.example.COM"}}
+	fk11 := []apiv1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+
 	fooTests := []struct {
-		lhs []apiv1.LoadBalancerIngress
-		rhs []apiv1.LoadBalancerIngress
-		er  bool
+		lhs       []apiv1.LoadBalancerIngress
+		rhs       []apiv1.LoadBalancerIngress
+		normalize bool
+		er        bool
 	}{
-		{fk1, fk1, true},
-		{fk2, fk1, false},
-		{fk3, fk1, false},
-		{fk4, fk1, false},
-		{fk1, nil, false},
-		{nil, nil, true},
-		{[]apiv1.LoadBalancerIngress{}, []apiv1.LoadBalancerIngress{}, true},
+		{fk1, fk1, false, true},
+		{fk2, fk1, false, false},
+		{fk3, fk1, false, false},
+		{fk4, fk1, false, false},
+		{fk1, nil, false, false},
+		{nil, nil, false, true},
+		{[]apiv1.LoadBalancerIngress{}, []apiv1.LoadBalancerIngress{}, false, true},
+		{fk5, fk1, false, false},
+		{fk6, fk1, false, false},
+		{fk7, fk8, false, false},
+		{fk1, fk1, true, true},
+		{fk2, fk1, true, false},
+		{fk3, fk1, true, false},
+		{fk4, fk1, true, false},
+		{fk1, nil, true, false},
+		{nil, []apiv1.LoadBalancerIngress{{}}, true, true},
+		{fk5, fk1, true, true},
+		{fk6, fk1, true, true},
+		{fk7, fk8, true, true},
+		{fk8, []apiv1.LoadBalancerIngress{{IP: "10.0.0.1", Hostname: "foo1"}}, true, false},
+		{fk9, fk1, false, true},
+		{fk9, fk1, true, true},
+		{fk10, fk11, false, false},
+		{fk10, fk11, true, true},
 	}
 
-	for _, fooTest := range fooTests {
-		r := ingressSliceEqual(fooTest.lhs, fooTest.rhs)
+	for i, fooTest := range fooTests {
+		fk := buildStatusSync()
+		fk.NormalizeStatusEntries = fooTest.normalize
+
+		r := fk.statusEqualFunc()(fooTest.lhs, fooTest.rhs)
 		if r != fooTest.er {
-			t.Errorf("returned %v but expected %v", r, fooTest.er)
+			t.Errorf("%v: returned %v but expected %v", i, r, fooTest.er)
+		}
+	}
+}
+
+func TestDiffLoadBalancerIngress(t *testing.T) {
+	testCases := []struct {
+		name    string
+		old     []apiv1.LoadBalancerIngress
+		cur     []apiv1.LoadBalancerIngress
+		added   []apiv1.LoadBalancerIngress
+		removed []apiv1.LoadBalancerIngress
+	}{
+		{
+			name: "equal",
+			old:  []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "foo.bar.com"}},
+			cur:  []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "foo.bar.com"}},
+		},
+		{
+			name:  "from empty",
+			cur:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+			added: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+		},
+		{
+			name:    "to empty",
+			old:     []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			removed: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+		},
+		{
+			name:    "replaced",
+			old:     []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+			cur:     []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}, {IP: "10.0.0.3"}, {Hostname: "foo.bar.com"}},
+			added:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.3"}, {Hostname: "foo.bar.com"}},
+			removed: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+		},
+		{
+			name: "reordered",
+			old:  []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+			cur:  []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}},
+		},
+		{
+			name: "hostname case",
+			old:  []apiv1.LoadBalancerIngress{{Hostname: "Foo.Bar.com"}},
+			cur:  []apiv1.LoadBalancerIngress{{Hostname: "foo.bar.com"}},
+		},
+		{
+			name:  "duplicated",
+			old:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			cur:   []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}, {IP: "10.0.0.2"}},
+			added: []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			added, removed := diffLoadBalancerIngress(tc.old, tc.cur)
+			if !reflect.DeepEqual(added, tc.added) {
+				t.Errorf("returned added %v but expected %v", added, tc.added)
+			}
+			if !reflect.DeepEqual(removed, tc.removed) {
+				t.Errorf("returned removed %v but expected %v", removed, tc.removed)
+			}
+		})
+	}
+
+	// the order is compared by ingressSliceEqual
+	reordered := testCases[4]
+	if ingressSliceEqual(reordered.old, reordered.cur) {
+		t.Errorf("expected %v and %v to be different", reordered.old, reordered.cur)
+	}
+}
+
+func TestNormalizeStatusEntries(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1, ,foo.bar.com,10.0.0.1,"
+	fk.NormalizeStatusEntries = true
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "foo.bar.com"}}
+	if status := fk.lastStatus.get(); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestUpdateStatusWithAddressPriority(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1,11.0.0.1,foo.bar.com"
+	fk.AddressPriority = []string{"11.0.0.0/8", "*.bar.com"}
+
+	err := fk.sync("just-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "11.0.0.1"},
+		{Hostname: "foo.bar.com"},
+		{IP: "10.0.0.1"},
+	}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
+
+func TestUpdateStatusWithMaxPublishedAddresses(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1,10.0.0.2,11.0.0.1,11.0.0.2,foo.bar.com"
+	fk.AddressPriority = []string{"11.0.0.0/8", "*.bar.com"}
+	fk.MaxPublishedAddresses = 3
+
+	err := fk.sync("just-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "11.0.0.1"},
+		{IP: "11.0.0.2"},
+		{Hostname: "foo.bar.com"},
+	}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	// the addresses are not truncated when the status is shorter than the limit
+	fk.MaxPublishedAddresses = 10
+	if status := fk.limitStatus(expected); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func buildNodeFilterClientSet() *testclient.Clientset {
+	pod := func(name, node string) apiv1.Pod {
+		return apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: apiv1.NamespaceDefault,
+				Labels: map[string]string{
+					"label_sig": "foo_pod",
+				},
+			},
+			Spec: apiv1.PodSpec{
+				NodeName: node,
+			},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodRunning,
+				Conditions: []apiv1.PodCondition{
+					{
+						Type:   apiv1.PodReady,
+						Status: apiv1.ConditionTrue,
+					},
+				},
+			},
+		}
+	}
+
+	node := func(name, ip string, nodeLabels map[string]string, taints []apiv1.Taint) apiv1.Node {
+		return apiv1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: nodeLabels,
+			},
+			Spec: apiv1.NodeSpec{
+				Taints: taints,
+			},
+			Status: apiv1.NodeStatus{
+				Addresses: []apiv1.NodeAddress{
+					{
+						Type:    apiv1.NodeInternalIP,
+						Address: ip,
+					},
+				},
+			},
+		}
+	}
+
+	return testclient.NewSimpleClientset(
+		&apiv1.PodList{Items: []apiv1.Pod{
+			pod("foo_edge", "foo_node_edge"),
+			pod("foo_worker", "foo_node_worker"),
+			pod("foo_master", "foo_node_master"),
+		}},
+		&apiv1.NodeList{Items: []apiv1.Node{
+			node("foo_node_edge", "12.0.0.1", map[string]string{"node-role": "edge", apiv1.LabelTopologyZone: "zone-a"}, nil),
+			node("foo_node_worker", "12.0.0.2", map[string]string{"node-role": "worker", apiv1.LabelTopologyZone: "zone-b"}, nil),
+			node("foo_node_master", "12.0.0.3", map[string]string{"node-role": "edge", apiv1.LabelTopologyZone: "zone-a"}, []apiv1.Taint{
+				{
+					Key:    "node-role.kubernetes.io/master",
+					Effect: apiv1.TaintEffectNoSchedule,
+				},
+			}),
+		}},
+	)
+}
+
+func TestRunningAddressesWithNodeFilters(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		selector      labels.Selector
+		publishTaints bool
+		zones         []string
+		expected      []string
+	}{
+		"tainted nodes are skipped by default": {
+			nil,
+			false,
+			nil,
+			[]string{"12.0.0.1", "12.0.0.2"},
+		},
+		"tainted nodes are included": {
+			nil,
+			true,
+			nil,
+			[]string{"12.0.0.1", "12.0.0.2", "12.0.0.3"},
+		},
+		"node selector": {
+			labels.SelectorFromSet(labels.Set{"node-role": "edge"}),
+			false,
+			nil,
+			[]string{"12.0.0.1"},
+		},
+		"node selector and tainted nodes": {
+			labels.SelectorFromSet(labels.Set{"node-role": "edge"}),
+			true,
+			nil,
+			[]string{"12.0.0.1", "12.0.0.3"},
+		},
+		"zones": {
+			nil,
+			false,
+			[]string{"zone-b"},
+			[]string{"12.0.0.2"},
+		},
+		"zones and tainted nodes": {
+			nil,
+			true,
+			[]string{"zone-a", "zone-c"},
+			[]string{"12.0.0.1", "12.0.0.3"},
+		},
+		"zones and node selector": {
+			labels.SelectorFromSet(labels.Set{"node-role": "edge"}),
+			false,
+			[]string{"zone-b"},
+			[]string{},
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.Client = buildNodeFilterClientSet()
+			fk.PublishService = ""
+			fk.NodeSelector = tc.selector
+			fk.PublishTaintedNodes = tc.publishTaints
+			fk.PublishZones = tc.zones
+
+			ra, err := fk.runningAddresses()
+			if err != nil {
+				t.Fatalf("unexpected error obtaining running address/es: %v", err)
+			}
+
+			sort.Strings(ra)
+			if !reflect.DeepEqual(tc.expected, ra) {
+				t.Errorf("returned %v but expected %v", ra, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRunningAddressesWithExcludeCIDRs(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	pod := func(name, node string) apiv1.Pod {
+		return apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: apiv1.NamespaceDefault,
+				Labels: map[string]string{
+					"label_sig": "foo_pod",
+				},
+			},
+			Spec: apiv1.PodSpec{
+				NodeName: node,
+			},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodRunning,
+				Conditions: []apiv1.PodCondition{
+					{
+						Type:   apiv1.PodReady,
+						Status: apiv1.ConditionTrue,
+					},
+				},
+			},
+		}
+	}
+
+	node := func(name, ip string) apiv1.Node {
+		return apiv1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Status: apiv1.NodeStatus{
+				Addresses: []apiv1.NodeAddress{
+					{
+						Type:    apiv1.NodeExternalIP,
+						Address: ip,
+					},
+				},
+			},
+		}
+	}
+
+	client := testclient.NewSimpleClientset(
+		&apiv1.PodList{Items: []apiv1.Pod{
+			pod("foo_public", "foo_node_public"),
+			pod("foo_link_local", "foo_node_link_local"),
+			pod("foo_link_local_v6", "foo_node_link_local_v6"),
+		}},
+		&apiv1.NodeList{Items: []apiv1.Node{
+			node("foo_node_public", "12.0.0.1"),
+			node("foo_node_link_local", "169.254.10.1"),
+			node("foo_node_link_local_v6", "fe80::1"),
+		}},
+	)
+
+	testCases := map[string]struct {
+		excludeCIDRs []string
+		expected     []string
+	}{
+		"without excluded CIDRs": {
+			nil,
+			[]string{"12.0.0.1", "169.254.10.1", "fe80::1"},
+		},
+		"link-local addresses": {
+			[]string{"169.254.0.0/16", "fe80::/10"},
+			[]string{"12.0.0.1"},
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			syncer, err := NewStatusSyncer(Config{
+				Client:            client,
+				IngressLister:     buildIngressLister(),
+				ExcludeCIDRs:      tc.excludeCIDRs,
+				MetricsRegisterer: prometheus.NewRegistry(),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ra, err := syncer.(*statusSync).runningAddresses()
+			if err != nil {
+				t.Fatalf("unexpected error obtaining running address/es: %v", err)
+			}
+
+			sort.Strings(ra)
+			if !reflect.DeepEqual(ra, tc.expected) {
+				t.Errorf("returned %v but expected %v", ra, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNewStatusSyncerWithInvalidExcludeCIDRs(t *testing.T) {
+	_, err := NewStatusSyncer(Config{
+		Client:            buildSimpleClientSet(),
+		IngressLister:     buildIngressLister(),
+		ExcludeCIDRs:      []string{"169.254.0.0/16", "10.0.0.1"},
+		MetricsRegisterer: prometheus.NewRegistry(),
+	})
+	if err == nil {
+		t.Errorf("expected an error creating the status syncer with an invalid CIDR")
+	}
+}
+
+func buildPublishServiceSelectorClientSet() *testclient.Clientset {
+	svc := func(namespace, name, ip string, svcLabels map[string]string) apiv1.Service {
+		return apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    svcLabels,
+			},
+			Spec: apiv1.ServiceSpec{
+				Type: apiv1.ServiceTypeLoadBalancer,
+			},
+			Status: apiv1.ServiceStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: ip}},
+				},
+			},
 		}
 	}
+
+	return testclient.NewSimpleClientset(
+		&apiv1.ServiceList{Items: []apiv1.Service{
+			svc("ingress-prod", "lb-prod", "20.0.0.1", map[string]string{"app": "ingress-lb", "tier": "edge"}),
+			svc("ingress-staging", "lb-staging", "20.0.0.2", map[string]string{"app": "staging-lb", "tier": "edge"}),
+			svc(apiv1.NamespaceDefault, "other", "20.0.0.3", map[string]string{"app": "other"}),
+		}},
+	)
+}
+
+func TestRunningAddressesWithPublishServiceSelector(t *testing.T) {
+	testCases := map[string]struct {
+		selector    labels.Selector
+		expected    []string
+		expectedErr bool
+	}{
+		"single matching service": {
+			labels.SelectorFromSet(labels.Set{"app": "ingress-lb"}),
+			[]string{"20.0.0.1"},
+			false,
+		},
+		"no matching service": {
+			labels.SelectorFromSet(labels.Set{"app": "missing"}),
+			nil,
+			true,
+		},
+		"multiple matching services": {
+			labels.SelectorFromSet(labels.Set{"tier": "edge"}),
+			nil,
+			true,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			fk := buildStatusSync()
+			fk.Client = buildPublishServiceSelectorClientSet()
+			fk.PublishService = ""
+			fk.PublishServiceSelector = tc.selector
+
+			ra, err := fk.runningAddresses()
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error but returned %v", ra)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error obtaining running address/es: %v", err)
+			}
+
+			if !reflect.DeepEqual(ra, tc.expected) {
+				t.Errorf("returned %v but expected %v", ra, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPublishServiceChangedWithSelector(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishServiceSelector = labels.SelectorFromSet(labels.Set{"app": "ingress-lb"})
+
+	old := &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lb-prod",
+			Namespace: "ingress-prod",
+			Labels:    map[string]string{"app": "ingress-lb"},
+		},
+	}
+
+	cur := old.DeepCopy()
+	cur.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: "20.0.0.1"}}
+	if !fk.publishServiceChanged(old, cur) {
+		t.Errorf("expected a change in the service matching the selector")
+	}
+
+	cur.Labels = map[string]string{"app": "other"}
+	if fk.publishServiceChanged(old, cur) {
+		t.Errorf("unexpected change in a service not matching the selector")
+	}
+}
+
+func TestNodeDeleted(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+	}
+
+	client := buildSimpleClientSet()
+
+	// a second controller pod running in foo_node_1
+	_, err := client.CoreV1().Pods(apiv1.NamespaceDefault).Create(context.TODO(), &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo4",
+			Namespace: apiv1.NamespaceDefault,
+			Labels: map[string]string{
+				"label_sig": "foo_pod",
+			},
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "foo_node_1",
+		},
+		Status: apiv1.PodStatus{
+			Phase: apiv1.PodRunning,
+			Conditions: []apiv1.PodCondition{
+				{
+					Type:   apiv1.PodReady,
+					Status: apiv1.ConditionTrue,
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	syncer, err := NewStatusSyncer(Config{
+		Client:            client,
+		IngressLister:     &clientIngressLister{client},
+		MetricsRegisterer: prometheus.NewRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fk := syncer.(*statusSync)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go fk.Run(stopCh)
+
+	waitForStatus := func(expected []apiv1.LoadBalancerIngress) {
+		err := wait.Poll(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+			ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			return reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected), nil
+		})
+		if err != nil {
+			t.Fatalf("expected Ingress status %v: %v", expected, err)
+		}
+	}
+
+	waitForStatus([]apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}, {IP: "11.0.0.2"}})
+
+	err = client.CoreV1().Nodes().Delete(context.TODO(), "foo_node_2", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitForStatus([]apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}})
+}
+
+func TestInitialSyncDelay(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	syncer, err := NewStatusSyncer(Config{
+		Client:               buildSimpleClientSet(),
+		PublishStatusAddress: "10.0.0.1",
+		IngressLister:        buildIngressLister(),
+		MetricsRegisterer:    prometheus.NewRegistry(),
+		InitialSyncDelay:     time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	fk := syncer.(*statusSync)
+	fk.clock = fakeClock
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		fk.Run(stopCh)
+		close(done)
+	}()
+	defer func() {
+		close(stopCh)
+		<-done
+	}()
+
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fakeClock.HasWaiters(), nil
+	})
+	if err != nil {
+		t.Fatalf("expected Run to wait the initial sync delay")
+	}
+
+	fakeClock.Step(59 * time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if status := fk.lastStatus.get(); status != nil {
+		t.Fatalf("expected no sync before the initial delay but the status is %v", status)
+	}
+
+	fakeClock.Step(time.Second)
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return fk.lastStatus.get() != nil, nil
+	})
+	if err != nil {
+		t.Fatalf("expected a sync after the initial delay")
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if status := fk.lastStatus.get(); !reflect.DeepEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestShutdownClearErrors(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	newIngress := func(name string) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: apiv1.NamespaceDefault,
+			},
+			Status: networking.IngressStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+				},
+			},
+		}
+	}
+
+	client := testclient.NewSimpleClientset(newIngress("foo_failed"), newIngress("foo_conflict"), newIngress("foo_cleared"))
+
+	var conflicts int32
+	client.PrependReactor("update", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ing := action.(k8stesting.UpdateAction).GetObject().(*networking.Ingress)
+		switch ing.Name {
+		case "foo_failed":
+			return true, nil, fmt.Errorf("unexpected error")
+		case "foo_conflict":
+			if atomic.AddInt32(&conflicts, 1) == 1 {
+				return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "ingresses"}, ing.Name, fmt.Errorf("modified"))
+			}
+		}
+
+		return false, nil, nil
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.UpdateStatusOnShutdown = true
+
+	err := fk.Shutdown()
+	if err == nil || !strings.Contains(err.Error(), "default/foo_failed") {
+		t.Fatalf("expected an error clearing default/foo_failed but returned %v", err)
+	}
+
+	for name, expected := range map[string]int{"foo_failed": 1, "foo_conflict": 0, "foo_cleared": 0} {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ing.Status.LoadBalancer.Ingress) != expected {
+			t.Errorf("%v: expected %v addresses but returned %v", name, expected, ing.Status.LoadBalancer.Ingress)
+		}
+	}
+
+	if errs := fk.UpdateErrors(); len(errs) != 1 {
+		t.Errorf("expected one update error but returned %v", errs)
+	}
+
+	// the cleared Ingresses are not updated again
+	atomic.StoreInt32(&conflicts, 0)
+	if err := fk.Shutdown(); err == nil {
+		t.Errorf("expected an error clearing default/foo_failed")
+	}
+	if atomic.LoadInt32(&conflicts) != 0 {
+		t.Errorf("unexpected update of the cleared Ingress default/foo_conflict")
+	}
+}
+
+func TestOnStatusUpdated(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_updated",
+				Namespace: apiv1.NamespaceDefault,
+			},
+		},
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_current",
+				Namespace: apiv1.NamespaceDefault,
+				Annotations: map[string]string{
+					parser.GetAnnotationWithPrefix(observedGenerationAnnotation): "0",
+				},
+			},
+			Status: networking.IngressStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+				},
+			},
+		},
+	)
+
+	var lock sync.Mutex
+	calls := map[string][]apiv1.LoadBalancerIngress{}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.OnStatusUpdated = func(key string, addrs []apiv1.LoadBalancerIngress) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		calls[key] = addrs
+	}
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the callback is only called for the written Ingresses
+	expected := map[string][]apiv1.LoadBalancerIngress{
+		"default/foo_updated": {{IP: "10.0.0.1"}},
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("returned %v but expected %v", calls, expected)
+	}
+}
+
+func TestReconcileDone(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_ingress_a",
+				Namespace: apiv1.NamespaceDefault,
+			},
+		},
+		&networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo_ingress_b",
+				Namespace: apiv1.NamespaceDefault,
+			},
+		},
+	)
+
+	done := make(chan string, 2)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.ReconcileDone = done
+	fk.setLeading(true)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fk.sync("just-test")
+	}()
+
+	// wait for the update of foo_ingress_b instead of sleeping
+	timeout := time.After(5 * time.Second)
+	for updated := false; !updated; {
+		select {
+		case key := <-done:
+			updated = key == "default/foo_ingress_b"
+		case <-timeout:
+			t.Fatalf("timed out waiting for the update of default/foo_ingress_b")
+		}
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if status := getIngressStatus(t, client, "foo_ingress_b"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-timeout:
+		t.Fatalf("timed out waiting for the sync")
+	}
+
+	// a consumer that stops reading does not block the sync
+	fk.ReconcileDone = make(chan string)
+	fk.PublishStatusAddress = "10.0.0.2"
+	go func() {
+		errCh <- fk.sync("just-test")
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the sync to finish without a consumer")
+	}
+
+	expected = []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	if status := getIngressStatus(t, client, "foo_ingress_b"); !ingressSliceEqual(status, expected) {
+		t.Errorf("returned %v but expected %v", status, expected)
+	}
+}
+
+func TestClearStatus(t *testing.T) {
+	client := buildSimpleClientSet()
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.IngressLister = &clientIngressLister{client}
+
+	statusOf := func(name string) []apiv1.LoadBalancerIngress {
+		ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return ing.Status.LoadBalancer.Ingress
+	}
+	before := statusOf("foo_ingress_different_class")
+	if len(before) == 0 {
+		t.Fatalf("expected the status of foo_ingress_different_class to be set")
+	}
+
+	// only the leader updates the status
+	if err := fk.ClearStatus(apiv1.NamespaceDefault, "foo_ingress_1"); err == nil {
+		t.Errorf("expected an error clearing the status without being the leader")
+	}
+	if len(statusOf("foo_ingress_1")) == 0 {
+		t.Errorf("expected the status to be unmodified")
+	}
+
+	fk.setLeading(true)
+
+	if err := fk.ClearStatus(apiv1.NamespaceDefault, "foo_ingress_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := statusOf("foo_ingress_1"); len(status) != 0 {
+		t.Errorf("expected an empty status but returned %v", status)
+	}
+
+	// the other Ingresses are not modified
+	if status := statusOf("foo_ingress_different_class"); !reflect.DeepEqual(status, before) {
+		t.Errorf("returned %v but expected %v", status, before)
+	}
+
+	if err := fk.ClearStatus(apiv1.NamespaceDefault, "foo_missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected a not found error but returned %v", err)
+	}
+}
+
+func TestClearStatusStaleIngress(t *testing.T) {
+	current := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo_ingress",
+			Namespace:       apiv1.NamespaceDefault,
+			ResourceVersion: "2",
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}},
+			},
+		},
+	}
+	client := testclient.NewSimpleClientset(current)
+
+	// the lister returns the Ingress before the last update of the status
+	stale := current.DeepCopy()
+	stale.ResourceVersion = "1"
+	stale.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.IngressLister = &staticIngressLister{[]*ingress.Ingress{{Ingress: *stale}}}
+	fk.setLeading(true)
+
+	// the conflict is retried with the current version of the Ingress
+	if err := fk.ClearStatus(apiv1.NamespaceDefault, "foo_ingress"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := getIngressStatus(t, client, "foo_ingress"); len(status) != 0 {
+		t.Errorf("expected an empty status but returned %v", status)
+	}
+}
+
+func TestEmptyIngressListerAfterStart(t *testing.T) {
+	client := testclient.NewSimpleClientset()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.clock = fakeClock
+	fk.started = fakeClock.Now()
+
+	// the sync is retried while the lister is empty shortly after the start
+	err := fk.sync("just-test")
+	if err != errIngressListerNotReady {
+		t.Fatalf("expected error %v but returned %v", errIngressListerNotReady, err)
+	}
+	if task.IsNonRetryableError(err) {
+		t.Errorf("expected a retryable error but returned %v", err)
+	}
+	if status := fk.lastStatus.get(); status != nil {
+		t.Errorf("expected no sync but the status is %v", status)
+	}
+
+	_, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Create(context.TODO(), &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	// an empty lister after the grace period is not an error
+	err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Delete(context.TODO(), "foo_ingress", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fakeClock.Step(emptyListerGracePeriod + time.Second)
+	if err := fk.sync("just-test"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
 }
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer used to trace the status updates
+const tracerName = "k8s.io/ingress-nginx/internal/ingress/status"
+
+// tracer returns the tracer of the configured TracerProvider, or of the
+// global one if none is configured. The global TracerProvider is a no-op
+// until it is replaced with otel.SetTracerProvider.
+func (s *statusSync) tracer() trace.Tracer {
+	tp := s.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return tp.Tracer(tracerName)
+}
+
+// startSpan starts a span named name as a child of the span in ctx
+func (s *statusSync) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// resultAttributes returns the span attributes describing the result of a sync
+func resultAttributes(result syncResult) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("ingress.updated", result.updated),
+		attribute.Int("ingress.skipped", result.skipped),
+		attribute.Int("ingress.failed", result.failed),
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSyncTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+
+	byName := map[string][]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+
+	expected := map[string]int{
+		"status.sync":             1,
+		"status.runningAddresses": 1,
+		"status.updateStatus":     1,
+		"status.write":            2,
+	}
+	for name, count := range expected {
+		if len(byName[name]) != count {
+			t.Fatalf("returned %v %v spans but expected %v", len(byName[name]), name, count)
+		}
+	}
+
+	sync := byName["status.sync"][0]
+	if sync.Parent.IsValid() {
+		t.Errorf("expected status.sync to be the root span")
+	}
+
+	parents := map[string]string{
+		"status.runningAddresses": "status.sync",
+		"status.updateStatus":     "status.sync",
+		"status.write":            "status.updateStatus",
+	}
+	for name, parentName := range parents {
+		parent := byName[parentName][0]
+		for _, span := range byName[name] {
+			if span.Parent.SpanID() != parent.SpanContext.SpanID() {
+				t.Errorf("expected %v to be a child of %v", name, parentName)
+			}
+		}
+	}
+
+	attrs := map[string]int64{}
+	for _, attr := range sync.Attributes {
+		attrs[string(attr.Key)] = attr.Value.AsInt64()
+	}
+	// foo_ingress_non_01 is returned by the lister but does not exist
+	if attrs["ingress.updated"] != 1 || attrs["ingress.skipped"] != 1 {
+		t.Errorf("returned %v updated and %v skipped Ingresses but expected 1 and 1", attrs["ingress.updated"], attrs["ingress.skipped"])
+	}
+}
+
+func TestSyncWithoutTracerProvider(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+// statusControllerVersionAnnotation is the annotation, without prefix,
+// containing the version of the controller that last wrote the status
+const statusControllerVersionAnnotation = "status-controller-version"
+
+// withControllerVersion returns a copy of the annotations with the version
+// of the controller
+func withControllerVersion(annotations map[string]string, version string) map[string]string {
+	return mergeAnnotations(annotations, map[string]string{
+		parser.GetAnnotationWithPrefix(statusControllerVersionAnnotation): version,
+	})
+}
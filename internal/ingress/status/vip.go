@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+// vipAnnotation is the annotation, without prefix, containing the key of
+// the VIP of an Ingress in the VIP pool ConfigMap
+const vipAnnotation = "vip"
+
+// vipPool contains the VIPs of the pool ConfigMap by key. The ConfigMap is
+// read on the first lookup, so syncs without VIP Ingresses do not get it.
+type vipPool struct {
+	load func() map[string]string
+
+	vips map[string]string
+}
+
+func (s *statusSync) newVIPPool(ctx context.Context) *vipPool {
+	return &vipPool{load: func() map[string]string {
+		return s.getVIPPool(ctx)
+	}}
+}
+
+// getVIPPool returns the valid VIPs of the VIPPoolConfigMap. Returns an
+// empty pool if the ConfigMap cannot be read.
+func (s *statusSync) getVIPPool(ctx context.Context) map[string]string {
+	vips := map[string]string{}
+
+	ns, name, err := k8s.ParseNameNS(s.VIPPoolConfigMap)
+	if err != nil {
+		klog.Warningf("invalid VIP pool ConfigMap %v: %v", s.VIPPoolConfigMap, err)
+		return vips
+	}
+
+	cm, err := s.Client.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("error getting VIP pool ConfigMap %v: %v", s.VIPPoolConfigMap, err)
+		return vips
+	}
+
+	for key, value := range cm.Data {
+		vip := strings.TrimSpace(value)
+		if !isValidAddress(vip) {
+			klog.Warningf("ignoring invalid VIP %q of key %v in ConfigMap %v", value, key, s.VIPPoolConfigMap)
+			continue
+		}
+
+		vips[key] = vip
+	}
+
+	return vips
+}
+
+// ingressVIP returns the VIP to publish in the status of the Ingress, set
+// with the vip annotation. Returns false if the annotation is not set, the
+// VIP pool is not configured or the key is not in the pool.
+func (s *statusSync) ingressVIP(ing *networking.Ingress, pool *vipPool) ([]apiv1.LoadBalancerIngress, bool) {
+	key := strings.TrimSpace(ing.Annotations[parser.GetAnnotationWithPrefix(vipAnnotation)])
+	if key == "" {
+		return nil, false
+	}
+
+	if s.VIPPoolConfigMap == "" {
+		klog.V(3).InfoS("ignoring VIP of Ingress (no VIP pool configured)", "namespace", ing.Namespace, "ingress", ing.Name)
+		return nil, false
+	}
+
+	if pool.vips == nil {
+		pool.vips = pool.load()
+	}
+
+	vip, ok := pool.vips[key]
+	if !ok {
+		klog.Warningf("VIP %v of Ingress %v/%v not found in ConfigMap %v, publishing the controller addresses", key, ing.Namespace, ing.Name, s.VIPPoolConfigMap)
+		return nil, false
+	}
+
+	return sliceToStatus([]string{vip}), true
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+)
+
+const testVIPPoolConfigMap = "vip-pool"
+
+func buildVIPPoolConfigMap() *apiv1.ConfigMap {
+	return &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testVIPPoolConfigMap,
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Data: map[string]string{
+			"vip-a":   "192.168.10.1",
+			"vip-b":   " vip-b.example.com ",
+			"invalid": "not a vip!",
+		},
+	}
+}
+
+func buildVIPIngress(name, vip string) *networking.Ingress {
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   apiv1.NamespaceDefault,
+			Annotations: map[string]string{},
+		},
+	}
+
+	if vip != "" {
+		ing.Annotations[parser.GetAnnotationWithPrefix(vipAnnotation)] = vip
+	}
+
+	return ing
+}
+
+func TestIngressVIP(t *testing.T) {
+	client := testclient.NewSimpleClientset(
+		buildVIPPoolConfigMap(),
+		buildVIPIngress("foo_vip_a", "vip-a"),
+		buildVIPIngress("foo_vip_b", "vip-b"),
+		buildVIPIngress("foo_missing", "vip-c"),
+		buildVIPIngress("foo_invalid", "invalid"),
+		buildVIPIngress("foo_no_vip", ""),
+	)
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.VIPPoolConfigMap = apiv1.NamespaceDefault + "/" + testVIPPoolConfigMap
+
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	controllerStatus := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	expected := map[string][]apiv1.LoadBalancerIngress{
+		"foo_vip_a":   {{IP: "192.168.10.1"}},
+		"foo_vip_b":   {{Hostname: "vip-b.example.com"}},
+		"foo_missing": controllerStatus,
+		"foo_invalid": controllerStatus,
+		"foo_no_vip":  controllerStatus,
+	}
+
+	for name, status := range expected {
+		if ips := getIngressStatus(t, client, name); !reflect.DeepEqual(ips, status) {
+			t.Errorf("%v: returned %v but expected %v", name, ips, status)
+		}
+	}
+}
+
+func TestIngressVIPWithoutPool(t *testing.T) {
+	testCases := map[string]string{
+		"pool disabled":          "",
+		"pool ConfigMap missing": apiv1.NamespaceDefault + "/missing",
+	}
+
+	for title, pool := range testCases {
+		t.Run(title, func(t *testing.T) {
+			client := testclient.NewSimpleClientset(
+				buildVIPPoolConfigMap(),
+				buildVIPIngress("foo_vip_a", "vip-a"),
+			)
+
+			fk := buildStatusSync()
+			fk.Client = client
+			fk.PublishService = ""
+			fk.PublishStatusAddress = "10.0.0.1"
+			fk.IngressLister = &clientIngressLister{client}
+			fk.VIPPoolConfigMap = pool
+
+			if _, err := fk.reconcile(context.TODO()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+			if ips := getIngressStatus(t, client, "foo_vip_a"); !reflect.DeepEqual(ips, expected) {
+				t.Errorf("returned %v but expected %v", ips, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// wildcardPrefix is the leftmost label of a wildcard host
+const wildcardPrefix = "*."
+
+// wildcardHostname returns the canonical hostname of a wildcard host, the
+// host without the wildcard label. Returns false if host is not a valid
+// wildcard host.
+func wildcardHostname(host string) (string, bool) {
+	if !strings.HasPrefix(host, wildcardPrefix) {
+		return "", false
+	}
+
+	hostname := strings.TrimPrefix(host, wildcardPrefix)
+	if len(validation.IsDNS1123Subdomain(hostname)) > 0 || !strings.Contains(hostname, ".") {
+		return "", false
+	}
+
+	return hostname, true
+}
+
+// ingressStatus returns the addresses to publish in the status of the
+// Ingress. With PublishWildcardHostnames the canonical hostnames of the
+// wildcard hosts of the rules are added.
+func (s *statusSync) ingressStatus(ing *networking.Ingress, addrs []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	if !s.PublishWildcardHostnames {
+		return addrs
+	}
+
+	status := addrs
+	for _, rule := range ing.Spec.Rules {
+		if !strings.HasPrefix(rule.Host, wildcardPrefix) {
+			continue
+		}
+
+		hostname, ok := wildcardHostname(rule.Host)
+		if !ok {
+			klog.Warningf("ignoring invalid wildcard host %q in Ingress %v/%v", rule.Host, ing.Namespace, ing.Name)
+			continue
+		}
+
+		if !hasHostname(status, hostname) {
+			status = append(status[:len(status):len(status)], apiv1.LoadBalancerIngress{Hostname: hostname})
+		}
+	}
+
+	if len(status) != len(addrs) {
+		s.sortStatus(status)
+	}
+
+	return status
+}
+
+func hasHostname(addrs []apiv1.LoadBalancerIngress, hostname string) bool {
+	for _, addr := range addrs {
+		if addr.Hostname == hostname {
+			return true
+		}
+	}
+
+	return false
+}
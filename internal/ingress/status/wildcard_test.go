@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWildcardHostname(t *testing.T) {
+	testCases := map[string]struct {
+		hostname string
+		valid    bool
+	}{
+		"*.apps.example.com": {"apps.example.com", true},
+		"*.example.com":      {"example.com", true},
+		"apps.example.com":   {"", false},
+		"*.com":              {"", false},
+		"*.*.example.com":    {"", false},
+		"foo.*.example.com":  {"", false},
+		"*.Apps.example.com": {"", false},
+		"*.":                 {"", false},
+	}
+
+	for host, tc := range testCases {
+		hostname, valid := wildcardHostname(host)
+		if hostname != tc.hostname || valid != tc.valid {
+			t.Errorf("%v: returned (%v, %v) but expected (%v, %v)", host, hostname, valid, tc.hostname, tc.valid)
+		}
+	}
+}
+
+func TestPublishWildcardHostnames(t *testing.T) {
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{
+				{Host: "*.apps.example.com"},
+				{Host: "foo.example.com"},
+				{Host: "*.invalid..example.com"},
+			},
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PublishWildcardHostnames = true
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "apps.example.com"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.skipped != 1 {
+		t.Errorf("expected one skipped Ingress but returned %+v", r)
+	}
+}
@@ -0,0 +1,434 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
+	typednetworking "k8s.io/client-go/kubernetes/typed/networking/v1beta1"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+)
+
+const (
+	// observedGenerationAnnotation is the annotation, without prefix, used to
+	// record the generation of the Ingress observed in the last status update.
+	// It is written with a patch of the Ingress, a request added to the first
+	// sync of each Ingress and to the sync after each change of its spec.
+	observedGenerationAnnotation = "status-observed-generation"
+
+	// statusOwnerAnnotation is the annotation, without prefix, containing
+	// the pod that wrote the current status of the Ingress
+	statusOwnerAnnotation = "status-owner"
+
+	// addressAnnotation is the annotation, without prefix, containing the
+	// addresses of the Ingress when the status is written as an annotation
+	addressAnnotation = "address"
+
+	// managedAddressesAnnotation is the annotation, without prefix, containing
+	// the addresses written by the controller in the status of the Ingress
+	managedAddressesAnnotation = "status-managed-addresses"
+)
+
+// StatusWriter writes the addresses where the controller is running in
+// the status of an object
+type StatusWriter interface {
+	Write(obj interface{}, addresses []apiv1.LoadBalancerIngress) error
+}
+
+// ingressStatusWriter updates the load balancer status of Ingresses
+type ingressStatusWriter struct {
+	client clientset.Interface
+
+	// timeout is the max duration of each API call. Disabled if zero
+	timeout time.Duration
+
+	// preserveUnknown keeps the entries of the status not written by the controller
+	preserveUnknown bool
+
+	// sort sorts the status after adding the preserved entries
+	sort func([]apiv1.LoadBalancerIngress)
+
+	// mergePatch writes the status with a merge patch instead of an update
+	mergePatch bool
+}
+
+// NewIngressStatusWriter returns a StatusWriter that updates the status
+// of *ingress.Ingress objects
+func NewIngressStatusWriter(client clientset.Interface) StatusWriter {
+	return &ingressStatusWriter{
+		client: client,
+	}
+}
+
+func (w *ingressStatusWriter) Write(obj interface{}, addresses []apiv1.LoadBalancerIngress) error {
+	ing, ok := obj.(*ingress.Ingress)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	ingClient := w.client.NetworkingV1beta1().Ingresses(ing.Namespace)
+	currIng, err := getIngress(ingClient, ing.Name, w.timeout)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("unexpected error searching Ingress %v/%v", ing.Namespace, ing.Name))
+	}
+
+	annotations := map[string]string{}
+
+	if w.preserveUnknown {
+		managedKey := parser.GetAnnotationWithPrefix(managedAddressesAnnotation)
+		if managed := annotationFromStatus(addresses); currIng.Annotations[managedKey] != managed {
+			annotations[managedKey] = managed
+		}
+
+		addresses = preserveUnknownEntries(currIng, addresses)
+		if w.sort != nil {
+			w.sort(addresses)
+		}
+	}
+
+	if !ingressSliceEqual(currIng.Status.LoadBalancer.Ingress, addresses) {
+		if isStatusModified(&ing.Ingress, currIng) {
+			klog.InfoS("skipping update of Ingress status (modified after the last read)", "namespace", currIng.Namespace, "ingress", currIng.Name, "currentValue", currIng.Status.LoadBalancer.Ingress)
+			return apierrors.NewConflict(networking.Resource("ingresses"), ing.Name, fmt.Errorf("the status was modified after it was read"))
+		}
+
+		added, removed := diffLoadBalancerIngress(currIng.Status.LoadBalancer.Ingress, addresses)
+		klog.InfoS("updating Ingress status", "namespace", currIng.Namespace, "ingress", currIng.Name, "currentValue", currIng.Status.LoadBalancer.Ingress, "newValue", addresses,
+			"added", added, "removed", removed)
+		currIng.Status.LoadBalancer.Ingress = addresses
+		if w.mergePatch {
+			currIng, err = patchIngressStatus(ingClient, currIng, w.timeout)
+		} else {
+			currIng, err = updateIngressStatus(ingClient, currIng, w.timeout)
+		}
+		if err != nil {
+			// the Ingress deleted during the sync is skipped
+			if !apierrors.IsNotFound(err) {
+				klog.Warningf("error updating ingress rule: %v", err)
+			}
+			return err
+		}
+
+		if owner := statusOwner(); owner != "" {
+			annotations[parser.GetAnnotationWithPrefix(statusOwnerAnnotation)] = owner
+		}
+	}
+
+	if !isGenerationObserved(currIng) {
+		annotations[parser.GetAnnotationWithPrefix(observedGenerationAnnotation)] = strconv.FormatInt(currIng.Generation, 10)
+	}
+
+	err = patchAnnotations(ingClient, currIng, annotations, nil, w.timeout)
+	if err != nil {
+		klog.Warningf("error updating annotations of ingress rule: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// isStatusModified returns true if the status of the Ingress in the API
+// server, currIng, changed after the Ingress ing was read, like after a
+// concurrent update by a new leader during a leader election handoff
+func isStatusModified(ing, currIng *networking.Ingress) bool {
+	if ing.ResourceVersion == "" || ing.ResourceVersion == currIng.ResourceVersion {
+		return false
+	}
+
+	return !ingressSliceEqual(ing.Status.LoadBalancer.Ingress, currIng.Status.LoadBalancer.Ingress)
+}
+
+// ingressAnnotationWriter writes the addresses of Ingresses in an
+// annotation instead of the status subresource
+type ingressAnnotationWriter struct {
+	client clientset.Interface
+
+	// timeout is the max duration of each API call. Disabled if zero
+	timeout time.Duration
+}
+
+// NewIngressAnnotationWriter returns a StatusWriter that writes the addresses
+// of *ingress.Ingress objects in the nginx.ingress.kubernetes.io/address annotation
+func NewIngressAnnotationWriter(client clientset.Interface) StatusWriter {
+	return &ingressAnnotationWriter{
+		client: client,
+	}
+}
+
+func (w *ingressAnnotationWriter) Write(obj interface{}, addresses []apiv1.LoadBalancerIngress) error {
+	ing, ok := obj.(*ingress.Ingress)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	ingClient := w.client.NetworkingV1beta1().Ingresses(ing.Namespace)
+	currIng, err := getIngress(ingClient, ing.Name, w.timeout)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("unexpected error searching Ingress %v/%v", ing.Namespace, ing.Name))
+	}
+
+	annotations := map[string]string{}
+
+	key := parser.GetAnnotationWithPrefix(addressAnnotation)
+	value := annotationFromStatus(addresses)
+	if currIng.Annotations[key] != value {
+		klog.InfoS("updating Ingress address annotation", "namespace", currIng.Namespace, "ingress", currIng.Name, "currentValue", currIng.Annotations[key], "newValue", value)
+		annotations[key] = value
+
+		if owner := statusOwner(); owner != "" {
+			annotations[parser.GetAnnotationWithPrefix(statusOwnerAnnotation)] = owner
+		}
+	}
+
+	if !isGenerationObserved(currIng) {
+		annotations[parser.GetAnnotationWithPrefix(observedGenerationAnnotation)] = strconv.FormatInt(currIng.Generation, 10)
+	}
+
+	err = patchAnnotations(ingClient, currIng, annotations, nil, w.timeout)
+	if err != nil {
+		klog.Warningf("error updating annotations of ingress rule: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// annotationFromStatus returns the addresses as a comma separated list
+func annotationFromStatus(addresses []apiv1.LoadBalancerIngress) string {
+	values := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		values = append(values, loadBalancerAddress(addr))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// statusFromAnnotation returns the addresses written by ingressAnnotationWriter
+func statusFromAnnotation(ing *networking.Ingress) []apiv1.LoadBalancerIngress {
+	value := ing.Annotations[parser.GetAnnotationWithPrefix(addressAnnotation)]
+	if value == "" {
+		return []apiv1.LoadBalancerIngress{}
+	}
+
+	return sliceToStatus(splitAddresses(value))
+}
+
+// preserveUnknownEntries returns the addresses plus the entries of the
+// current status of the Ingress not written by the controller. If the
+// written addresses were not recorded but the status has an owner, all
+// the entries are considered written by the controller.
+func preserveUnknownEntries(ing *networking.Ingress, addresses []apiv1.LoadBalancerIngress) []apiv1.LoadBalancerIngress {
+	value, ok := ing.Annotations[parser.GetAnnotationWithPrefix(managedAddressesAnnotation)]
+	if !ok && ing.Annotations[parser.GetAnnotationWithPrefix(statusOwnerAnnotation)] != "" {
+		return addresses
+	}
+
+	managed := sets.NewString()
+	if value != "" {
+		managed.Insert(splitAddresses(value)...)
+	}
+
+	known := sets.NewString()
+	for _, addr := range addresses {
+		known.Insert(loadBalancerAddress(addr))
+	}
+
+	result := append([]apiv1.LoadBalancerIngress{}, addresses...)
+	for _, cur := range ing.Status.LoadBalancer.Ingress {
+		addr := loadBalancerAddress(cur)
+		if managed.Has(addr) || known.Has(addr) {
+			continue
+		}
+
+		result = append(result, cur)
+		known.Insert(addr)
+	}
+
+	return result
+}
+
+// loadBalancerAddress returns the IP of the entry, or the hostname if empty
+func loadBalancerAddress(addr apiv1.LoadBalancerIngress) string {
+	if addr.IP != "" {
+		return addr.IP
+	}
+
+	return addr.Hostname
+}
+
+// statusOwner returns the namespace/name of the pod running the controller
+func statusOwner() string {
+	if k8s.IngressPodDetails == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v/%v", k8s.IngressPodDetails.Namespace, k8s.IngressPodDetails.Name)
+}
+
+// isGenerationObserved checks if the current generation of the Ingress
+// was already recorded by the controller
+func isGenerationObserved(ing *networking.Ingress) bool {
+	key := parser.GetAnnotationWithPrefix(observedGenerationAnnotation)
+	return ing.Annotations[key] == strconv.FormatInt(ing.Generation, 10)
+}
+
+// mergeAnnotations returns a copy of the annotations with the extra ones.
+// Returns the annotations unmodified if there are no extra annotations.
+func mergeAnnotations(annotations, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return annotations
+	}
+
+	result := make(map[string]string, len(annotations)+len(extra))
+	for key, value := range annotations {
+		result[key] = value
+	}
+
+	for key, value := range extra {
+		result[key] = value
+	}
+
+	return result
+}
+
+// staleAnnotation returns the annotation to remove from the Ingress, if set
+func staleAnnotation(ing *networking.Ingress, key string) []string {
+	if _, ok := ing.Annotations[key]; !ok {
+		return nil
+	}
+
+	return []string{key}
+}
+
+// patchAnnotations sets the annotations in the Ingress and removes the ones
+// in removed using a single patch
+func patchAnnotations(ingClient typednetworking.IngressInterface, ing *networking.Ingress, annotations map[string]string, removed []string, timeout time.Duration) error {
+	if len(annotations) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// a null value removes the annotation in a merge patch
+	values := make(map[string]interface{}, len(annotations)+len(removed))
+	for _, key := range removed {
+		values[key] = nil
+	}
+	for key, value := range annotations {
+		values[key] = value
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": values,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = callWithTimeout(timeout, func(ctx context.Context) (*networking.Ingress, error) {
+		return ingClient.Patch(ctx, ing.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	})
+	return err
+}
+
+// getIngress returns the current version of the Ingress from the API server
+func getIngress(ingClient typednetworking.IngressInterface, name string, timeout time.Duration) (*networking.Ingress, error) {
+	return callWithTimeout(timeout, func(ctx context.Context) (*networking.Ingress, error) {
+		return ingClient.Get(ctx, name, metav1.GetOptions{})
+	})
+}
+
+// updateIngressStatus updates the status subresource of the Ingress
+func updateIngressStatus(ingClient typednetworking.IngressInterface, ing *networking.Ingress, timeout time.Duration) (*networking.Ingress, error) {
+	return callWithTimeout(timeout, func(ctx context.Context) (*networking.Ingress, error) {
+		return ingClient.UpdateStatus(ctx, ing, metav1.UpdateOptions{})
+	})
+}
+
+// patchIngressStatus writes the load balancer status of the Ingress with a
+// merge patch of the status subresource, leaving the rest of the object
+// untouched. An empty status is sent as null to remove the addresses.
+func patchIngressStatus(ingClient typednetworking.IngressInterface, ing *networking.Ingress, timeout time.Duration) (*networking.Ingress, error) {
+	var addresses interface{}
+	if len(ing.Status.LoadBalancer.Ingress) > 0 {
+		addresses = ing.Status.LoadBalancer.Ingress
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"loadBalancer": map[string]interface{}{
+				"ingress": addresses,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return callWithTimeout(timeout, func(ctx context.Context) (*networking.Ingress, error) {
+		return ingClient.Patch(ctx, ing.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	})
+}
+
+// ingressResult contains the values returned by an Ingress API call
+type ingressResult struct {
+	ing *networking.Ingress
+	err error
+}
+
+// callWithTimeout runs the API call fn with a context canceled after timeout.
+// If fn does not return in time a retryable timeout error is returned without
+// waiting for it. A zero timeout disables the limit.
+func callWithTimeout(timeout time.Duration, fn func(ctx context.Context) (*networking.Ingress, error)) (*networking.Ingress, error) {
+	if timeout <= 0 {
+		return fn(context.TODO())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resultCh := make(chan ingressResult, 1)
+	go func() {
+		ing, err := fn(ctx)
+		resultCh <- ingressResult{ing, err}
+	}()
+
+	select {
+	case r := <-resultCh:
+		return r.ing, r.err
+	case <-ctx.Done():
+		return nil, apierrors.NewTimeoutError(fmt.Sprintf("the API call did not finish in %v", timeout), 0)
+	}
+}
@@ -0,0 +1,430 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s.io/ingress-nginx/internal/ingress"
+	"k8s.io/ingress-nginx/internal/ingress/annotations/parser"
+	"k8s.io/ingress-nginx/internal/k8s"
+	"k8s.io/ingress-nginx/internal/task"
+)
+
+type fakeStatusWriter struct {
+	sync.Mutex
+
+	writes map[string][]apiv1.LoadBalancerIngress
+}
+
+func (w *fakeStatusWriter) Write(obj interface{}, addresses []apiv1.LoadBalancerIngress) error {
+	w.Lock()
+	defer w.Unlock()
+
+	ing := obj.(*ingress.Ingress)
+	w.writes[ing.Namespace+"/"+ing.Name] = addresses
+	return nil
+}
+
+func TestCustomStatusWriter(t *testing.T) {
+	writer := &fakeStatusWriter{
+		writes: map[string][]apiv1.LoadBalancerIngress{},
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1,foo.bar.com"
+	fk.StatusWriter = writer
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 2 {
+		t.Errorf("returned %v updates but expected %v", r.updated, 2)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.1"},
+		{Hostname: "foo.bar.com"},
+	}
+	for _, key := range []string{"default/foo_ingress_non_01", "default/foo_ingress_1"} {
+		if !reflect.DeepEqual(writer.writes[key], expected) {
+			t.Errorf("%v: returned %v but expected %v", key, writer.writes[key], expected)
+		}
+	}
+}
+
+func TestIngressStatusWriterInvalidObject(t *testing.T) {
+	w := NewIngressStatusWriter(buildSimpleClientSet())
+
+	err := w.Write(&apiv1.Service{}, []apiv1.LoadBalancerIngress{})
+	if err == nil {
+		t.Errorf("expected an error writing the status of an unexpected type")
+	}
+}
+
+func TestStatusOwnerAnnotation(t *testing.T) {
+	k8s.IngressPodDetails = &k8s.PodInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_base_pod",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	}
+
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+
+	if err := fk.sync("just-test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := parser.GetAnnotationWithPrefix(statusOwnerAnnotation)
+
+	ing, err := fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ing.Annotations[key] != "default/foo_base_pod" {
+		t.Errorf("returned %v but expected %v", ing.Annotations[key], "default/foo_base_pod")
+	}
+
+	// the status of foo_ingress_2 is not updated because it is not returned by the lister
+	ing, err = fk.Client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress_2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ing.Annotations[key]; ok {
+		t.Errorf("unexpected status owner annotation in Ingress without status update")
+	}
+}
+
+func TestStatusToAnnotation(t *testing.T) {
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo_ingress",
+			Namespace:  apiv1.NamespaceDefault,
+			Generation: 1,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}},
+			},
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "foo.bar.com,10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.StatusToAnnotation = true
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := parser.GetAnnotationWithPrefix(addressAnnotation)
+	if ing.Annotations[key] != "10.0.0.1,foo.bar.com" {
+		t.Errorf("returned %v but expected %v", ing.Annotations[key], "10.0.0.1,foo.bar.com")
+	}
+
+	// the status subresource is not modified
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.skipped != 1 {
+		t.Errorf("expected one skipped Ingress but returned %+v", r)
+	}
+}
+
+func TestPreserveUnknownStatusEntries(t *testing.T) {
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "192.0.2.10"}},
+			},
+		},
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.PreserveUnknownStatusEntries = true
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {IP: "192.0.2.10"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+
+	key := parser.GetAnnotationWithPrefix(managedAddressesAnnotation)
+	if ing.Annotations[key] != "10.0.0.1" {
+		t.Errorf("returned %v but expected %v", ing.Annotations[key], "10.0.0.1")
+	}
+
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.skipped != 1 {
+		t.Errorf("expected one skipped Ingress but returned %+v", r)
+	}
+
+	// the previous address written by the controller is replaced
+	fk.PublishStatusAddress = "10.0.0.2"
+	if _, err := fk.reconcile(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}, {IP: "192.0.2.10"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
+
+func TestAPICallTimeout(t *testing.T) {
+	fk := buildStatusSync()
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.APICallTimeout = 50 * time.Millisecond
+
+	client := fk.Client.(*testclient.Clientset)
+	client.PrependReactor("get", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		time.Sleep(time.Second)
+		return false, nil, nil
+	})
+
+	start := time.Now()
+	err := fk.sync("just-test")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the API calls to be abandoned after the timeout but the sync took %v", elapsed)
+	}
+
+	if err == nil {
+		t.Fatalf("expected an error to requeue the sync")
+	}
+	if task.IsNonRetryableError(err) {
+		t.Errorf("expected a retryable error but returned %v", err)
+	}
+
+	errs := fk.UpdateErrors()
+	if len(errs) != 2 {
+		t.Errorf("returned %v errors but expected %v", len(errs), 2)
+	}
+
+	for key, ue := range errs {
+		if !strings.Contains(ue.Message, "did not finish") {
+			t.Errorf("%v: unexpected error %v", key, ue.Message)
+		}
+	}
+}
+
+type staticIngressLister struct {
+	ingresses []*ingress.Ingress
+}
+
+func (l *staticIngressLister) ListIngresses() []*ingress.Ingress {
+	return l.ingresses
+}
+
+func TestConcurrentStatusUpdate(t *testing.T) {
+	newer := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo_ingress",
+			Namespace:       apiv1.NamespaceDefault,
+			ResourceVersion: "2",
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.3"}},
+			},
+		},
+	}
+	client := testclient.NewSimpleClientset(newer)
+
+	// the lister returns the Ingress before the update of the new leader
+	stale := newer.DeepCopy()
+	stale.ResourceVersion = "1"
+	stale.Status.LoadBalancer.Ingress = []apiv1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	lister := &staticIngressLister{[]*ingress.Ingress{{Ingress: *stale}}}
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = lister
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.failed != 1 || r.retryable != 1 {
+		t.Errorf("expected one retryable failed update but returned %+v", r)
+	}
+
+	ing, err := client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []apiv1.LoadBalancerIngress{{IP: "10.0.0.3"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("expected the newer status %v to be kept but returned %v", expected, ing.Status.LoadBalancer.Ingress)
+	}
+
+	// the status is updated once the lister contains the newer version
+	lister.ingresses = []*ingress.Ingress{{Ingress: *newer}}
+	r, err = fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	ing, err = client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault).Get(context.TODO(), "foo_ingress", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+	if !reflect.DeepEqual(ing.Status.LoadBalancer.Ingress, expected) {
+		t.Errorf("returned %v but expected %v", ing.Status.LoadBalancer.Ingress, expected)
+	}
+}
+
+func TestUseMergePatch(t *testing.T) {
+	client := testclient.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+	})
+
+	var patches []string
+	client.PrependReactor("*", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			t.Errorf("unexpected update of the status subresource")
+		}
+		if patch, ok := action.(k8stesting.PatchAction); ok && action.GetSubresource() == "status" {
+			patches = append(patches, string(patch.GetPatch()))
+		}
+		return false, nil, nil
+	})
+
+	fk := buildStatusSync()
+	fk.Client = client
+	fk.PublishService = ""
+	fk.PublishStatusAddress = "10.0.0.1"
+	fk.IngressLister = &clientIngressLister{client}
+	fk.UseMergePatch = true
+
+	r, err := fk.reconcile(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.updated != 1 {
+		t.Errorf("expected one updated Ingress but returned %+v", r)
+	}
+
+	expected := []string{`{"status":{"loadBalancer":{"ingress":[{"ip":"10.0.0.1"}]}}}`}
+	if !reflect.DeepEqual(patches, expected) {
+		t.Errorf("returned patches %v but expected %v", patches, expected)
+	}
+
+	if status := getIngressStatus(t, client, "foo_ingress"); !ingressSliceEqual(status, []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}}) {
+		t.Errorf("unexpected status %v", status)
+	}
+}
+
+func TestPatchIngressStatusEmpty(t *testing.T) {
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo_ingress",
+			Namespace: apiv1.NamespaceDefault,
+		},
+		Status: networking.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+	client := testclient.NewSimpleClientset(ing)
+
+	// the addresses are removed with a null value
+	empty := ing.DeepCopy()
+	empty.Status.LoadBalancer.Ingress = nil
+	if _, err := patchIngressStatus(client.NetworkingV1beta1().Ingresses(apiv1.NamespaceDefault), empty, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status := getIngressStatus(t, client, "foo_ingress"); len(status) != 0 {
+		t.Errorf("expected an empty status but returned %v", status)
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"net"
+	"strings"
+)
+
+// isZonedIP returns true if addr is an IPv6 address with a zone identifier,
+// like the link-local fe80::1%eth0. The zone is only meaningful in the node
+// reporting the address, so these addresses are not published.
+func isZonedIP(addr string) bool {
+	i := strings.LastIndex(addr, "%")
+	if i <= 0 || i == len(addr)-1 {
+		return false
+	}
+
+	ip := net.ParseIP(addr[:i])
+	return ip != nil && ip.To4() == nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestIsZonedIP(t *testing.T) {
+	testCases := map[string]bool{
+		"fe80::1%eth0":       true,
+		"fe80::1%2":          true,
+		"fe80::1":            false,
+		"fe80::1%":           false,
+		"10.0.0.1%eth0":      false,
+		"10.0.0.1":           false,
+		"lb.example.com":     false,
+		"%eth0":              false,
+		"lb.example.com%eth": false,
+	}
+
+	for addr, expected := range testCases {
+		if zoned := isZonedIP(addr); zoned != expected {
+			t.Errorf("%v: returned %v but expected %v", addr, zoned, expected)
+		}
+	}
+}
+
+func TestSliceToStatusZonedIP(t *testing.T) {
+	r := sliceToStatus([]string{"fe80::1%eth0", "10.0.0.1", "2001:db8::68", "lb.example.com"})
+
+	// the zoned address is not published as a hostname
+	expected := []apiv1.LoadBalancerIngress{
+		{IP: "10.0.0.1"},
+		{IP: "2001:db8::68"},
+		{Hostname: "lb.example.com"},
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("returned %v but expected %v", r, expected)
+	}
+}
@@ -50,6 +50,12 @@ func GetNodeIPOrName(kubeClient clientset.Interface, name string, useInternalIP
 		return ""
 	}
 
+	return GetNodeAddress(node, useInternalIP)
+}
+
+// GetNodeAddress returns the external IP address of the node, or the
+// internal one if the node has no external IP or useInternalIP is true
+func GetNodeAddress(node *apiv1.Node, useInternalIP bool) string {
 	defaultOrInternalIP := ""
 	for _, address := range node.Status.Addresses {
 		if address.Type == apiv1.NodeInternalIP {
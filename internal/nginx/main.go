@@ -36,6 +36,10 @@ import (
 // ProfilerPort port used by the ingress controller to expose the Go Profiler when it is enabled.
 var ProfilerPort = 10245
 
+// StatusDiffPath is the path of the profiler server returning the Ingresses
+// with a status different from the addresses to publish
+const StatusDiffPath = "/debug/status-diff"
+
 // TemplatePath path of the NGINX template
 var TemplatePath = "/etc/nginx/template/nginx.tmpl"
 
@@ -60,8 +64,15 @@ var StreamPort = 10247
 
 // NewGetStatusRequest creates a new GET request to the internal NGINX status server
 func NewGetStatusRequest(path string) (int, []byte, error) {
-	url := fmt.Sprintf("http://127.0.0.1:%v%v", StatusPort, path)
+	return newGetRequest(fmt.Sprintf("http://127.0.0.1:%v%v", StatusPort, path))
+}
+
+// NewGetProfilerRequest creates a new GET request to the profiler server of the controller
+func NewGetProfilerRequest(path string) (int, []byte, error) {
+	return newGetRequest(fmt.Sprintf("http://127.0.0.1:%v%v", ProfilerPort, path))
+}
 
+func newGetRequest(url string) (int, []byte, error) {
 	client := http.Client{}
 	res, err := client.Get(url)
 	if err != nil {
@@ -17,7 +17,10 @@ limitations under the License.
 package task
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -43,10 +46,14 @@ type Queue struct {
 	sync func(interface{}) error
 	// workerDone is closed when the worker exits
 	workerDone chan bool
+	// closeWorkerDone closes workerDone only once
+	closeWorkerDone sync.Once
 	// fn makes a key for an API object
 	fn func(obj interface{}) (interface{}, error)
 	// lastSync is the Unix epoch time of the last execution of 'sync'
 	lastSync int64
+	// workers is the number of concurrent workers processing the queue
+	workers int
 }
 
 // Element represents one item of the queue
@@ -54,11 +61,25 @@ type Element struct {
 	Key         interface{}
 	Timestamp   int64
 	IsSkippable bool
+	// Enqueued is the Unix epoch time the element was added to the queue
+	Enqueued int64
+	// Isolated elements are never skipped and their sync does not update
+	// the time of the last sync, as they do not cover the other elements
+	Isolated bool
 }
 
 // Run starts processing elements in the queue
 func (t *Queue) Run(period time.Duration, stopCh <-chan struct{}) {
-	wait.Until(t.worker, period, stopCh)
+	var wg sync.WaitGroup
+	for i := 0; i < t.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(t.worker, period, stopCh)
+		}()
+	}
+
+	wg.Wait()
 }
 
 // EnqueueTask enqueues ns/name of the given api object in the task queue.
@@ -72,18 +93,31 @@ func (t *Queue) EnqueueSkippableTask(obj interface{}) {
 	t.enqueue(obj, true)
 }
 
+// EnqueueIsolatedTask enqueues ns/name of the given api object in the task
+// queue. The element is synced even if other elements were synced after it
+// was enqueued, and its sync does not skip the elements enqueued before.
+func (t *Queue) EnqueueIsolatedTask(obj interface{}) {
+	t.add(obj, time.Now().UnixNano(), true)
+}
+
 // enqueue enqueues ns/name of the given api object in the task queue.
 func (t *Queue) enqueue(obj interface{}, skippable bool) {
-	if t.IsShuttingDown() {
-		klog.ErrorS(nil, "queue has been shutdown, failed to enqueue", "key", obj)
-		return
-	}
-
 	ts := time.Now().UnixNano()
 	if !skippable {
 		// make sure the timestamp is bigger than lastSync
 		ts = time.Now().Add(24 * time.Hour).UnixNano()
 	}
+
+	t.add(obj, ts, false)
+}
+
+// add adds the element of the given api object to the task queue
+func (t *Queue) add(obj interface{}, ts int64, isolated bool) {
+	if t.IsShuttingDown() {
+		klog.ErrorS(nil, "queue has been shutdown, failed to enqueue", "key", obj)
+		return
+	}
+
 	klog.V(3).InfoS("queuing", "item", obj)
 	key, err := t.fn(obj)
 	if err != nil {
@@ -93,6 +127,8 @@ func (t *Queue) enqueue(obj interface{}, skippable bool) {
 	t.queue.Add(Element{
 		Key:       key,
 		Timestamp: ts,
+		Enqueued:  time.Now().UnixNano(),
+		Isolated:  isolated,
 	})
 }
 
@@ -110,16 +146,17 @@ func (t *Queue) worker() {
 	for {
 		key, quit := t.queue.Get()
 		if quit {
-			if !isClosed(t.workerDone) {
+			t.closeWorkerDone.Do(func() {
 				close(t.workerDone)
-			}
+			})
 			return
 		}
 		ts := time.Now().UnixNano()
 
 		item := key.(Element)
-		if t.lastSync > item.Timestamp {
-			klog.V(3).InfoS("skipping sync", "key", item.Key, "last", t.lastSync, "now", item.Timestamp)
+		lastSync := atomic.LoadInt64(&t.lastSync)
+		if !item.Isolated && lastSync > item.Timestamp {
+			klog.V(3).InfoS("skipping sync", "key", item.Key, "last", lastSync, "now", item.Timestamp)
 			t.queue.Forget(key)
 			t.queue.Done(key)
 			continue
@@ -127,28 +164,39 @@ func (t *Queue) worker() {
 
 		klog.V(3).InfoS("syncing", "key", item.Key)
 		if err := t.sync(key); err != nil {
-			klog.ErrorS(err, "requeuing", "key", item.Key)
-			t.queue.AddRateLimited(Element{
-				Key:       item.Key,
-				Timestamp: time.Now().UnixNano(),
-			})
+			if IsNonRetryableError(err) {
+				klog.ErrorS(err, "dropping", "key", item.Key)
+				t.queue.Forget(key)
+			} else {
+				klog.ErrorS(err, "requeuing", "key", item.Key)
+				now := time.Now().UnixNano()
+				t.queue.AddRateLimited(Element{
+					Key:       item.Key,
+					Timestamp: now,
+					Enqueued:  now,
+					Isolated:  item.Isolated,
+				})
+			}
 		} else {
 			t.queue.Forget(key)
-			t.lastSync = ts
+			if !item.Isolated {
+				storeMax(&t.lastSync, ts)
+			}
 		}
 
 		t.queue.Done(key)
 	}
 }
 
-func isClosed(ch <-chan bool) bool {
-	select {
-	case <-ch:
-		return true
-	default:
+// storeMax stores val in addr unless addr contains a bigger value, so the
+// time of the last sync does not go back when the workers finish out of order
+func storeMax(addr *int64, val int64) {
+	for {
+		cur := atomic.LoadInt64(addr)
+		if val <= cur || atomic.CompareAndSwapInt64(addr, cur, val) {
+			return
+		}
 	}
-
-	return false
 }
 
 // Shutdown shuts down the work queue and waits for the worker to ACK
@@ -157,6 +205,11 @@ func (t *Queue) Shutdown() {
 	<-t.workerDone
 }
 
+// Len returns the number of elements waiting in the queue
+func (t *Queue) Len() int {
+	return t.queue.Len()
+}
+
 // IsShuttingDown returns if the method Shutdown was invoked
 func (t *Queue) IsShuttingDown() bool {
 	return t.queue.ShuttingDown()
@@ -168,13 +221,30 @@ func NewTaskQueue(syncFn func(interface{}) error) *Queue {
 	return NewCustomTaskQueue(syncFn, nil)
 }
 
+// NewTaskQueueN creates a new task queue with the given sync function and
+// number of workers. The sync function must be safe for concurrent use.
+func NewTaskQueueN(syncFn func(interface{}) error, workers int) *Queue {
+	return NewCustomTaskQueueN(syncFn, nil, workers)
+}
+
 // NewCustomTaskQueue ...
 func NewCustomTaskQueue(syncFn func(interface{}) error, fn func(interface{}) (interface{}, error)) *Queue {
+	return NewCustomTaskQueueN(syncFn, fn, 1)
+}
+
+// NewCustomTaskQueueN creates a new task queue with the given sync and key
+// functions, processed by the given number of workers (at least one)
+func NewCustomTaskQueueN(syncFn func(interface{}) error, fn func(interface{}) (interface{}, error), workers int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+
 	q := &Queue{
 		queue:      workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		sync:       syncFn,
 		workerDone: make(chan bool),
 		fn:         fn,
+		workers:    workers,
 	}
 
 	if fn == nil {
@@ -184,6 +254,36 @@ func NewCustomTaskQueue(syncFn func(interface{}) error, fn func(interface{}) (in
 	return q
 }
 
+// nonRetryableError is an error returned by the sync function
+// for an element that must not be requeued
+type nonRetryableError struct {
+	err error
+}
+
+func (e nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+// NewNonRetryableError wraps err to indicate the element that
+// failed to sync must not be requeued
+func NewNonRetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return nonRetryableError{err: err}
+}
+
+// IsNonRetryableError returns true if err was created by NewNonRetryableError
+func IsNonRetryableError(err error) bool {
+	var e nonRetryableError
+	return errors.As(err, &e)
+}
+
 // GetDummyObject returns a valid object that can be used in the Queue
 func GetDummyObject(name string) *metav1.ObjectMeta {
 	return &metav1.ObjectMeta{
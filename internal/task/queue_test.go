@@ -157,3 +157,186 @@ func TestSkipEnqueue(t *testing.T) {
 	// shutdown queue before exit
 	q.Shutdown()
 }
+
+func TestMultipleWorkers(t *testing.T) {
+	var processed, running, maxRunning int32
+
+	syncFn := func(interface{}) error {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if cur <= max || atomic.CompareAndSwapInt32(&maxRunning, max, cur) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&processed, 1)
+		return nil
+	}
+
+	q := NewTaskQueueN(syncFn, 4)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	// run queue
+	go q.Run(time.Second, stopCh)
+
+	for i := 0; i < 20; i++ {
+		q.EnqueueTask(GetDummyObject(fmt.Sprintf("key-%v", i)))
+	}
+
+	// wait for 'syncFn'
+	for i := 0; i < 100 && atomic.LoadInt32(&processed) < 20; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if p := atomic.LoadInt32(&processed); p != 20 {
+		t.Errorf("processed should be 20, but is %d", p)
+	}
+	if m := atomic.LoadInt32(&maxRunning); m < 2 {
+		t.Errorf("expected concurrent workers, but the max number of running syncs is %d", m)
+	}
+
+	// shutdown queue before exit
+	q.Shutdown()
+}
+
+func TestRequeueOnError(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected int32
+	}{
+		"retryable error": {
+			fmt.Errorf("transient error"),
+			2,
+		},
+		"non retryable error": {
+			NewNonRetryableError(fmt.Errorf("permanent error")),
+			1,
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			var calls int32
+
+			syncFn := func(interface{}) error {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					return tc.err
+				}
+
+				return nil
+			}
+
+			q := NewTaskQueue(syncFn)
+			stopCh := make(chan struct{})
+			// run queue
+			go q.Run(time.Second, stopCh)
+
+			q.EnqueueTask(GetDummyObject("key"))
+			// wait for the requeued element, if any
+			time.Sleep(100 * time.Millisecond)
+
+			if c := atomic.LoadInt32(&calls); c != tc.expected {
+				t.Errorf("sync should be called %d times, but was called %d times", tc.expected, c)
+			}
+
+			q.Shutdown()
+		})
+	}
+}
+
+func TestIsolatedTask(t *testing.T) {
+	var fullSyncs, isolatedSyncs int32
+
+	var q *Queue
+	syncFn := func(key interface{}) error {
+		if key.(Element).Isolated {
+			atomic.AddInt32(&isolatedSyncs, 1)
+			return nil
+		}
+
+		if atomic.AddInt32(&fullSyncs, 1) == 1 {
+			// enqueued before the retry of the failed element
+			q.EnqueueIsolatedTask(GetDummyObject("isolated"))
+			return fmt.Errorf("transient error")
+		}
+
+		return nil
+	}
+
+	q = NewTaskQueue(syncFn)
+	stopCh := make(chan struct{})
+	// run queue
+	go q.Run(time.Second, stopCh)
+
+	q.EnqueueTask(GetDummyObject("full"))
+	// wait for the requeued element
+	time.Sleep(100 * time.Millisecond)
+
+	if c := atomic.LoadInt32(&isolatedSyncs); c != 1 {
+		t.Errorf("isolated sync should be called once, but was called %d times", c)
+	}
+	// the isolated element does not skip the retry of the failed one
+	if c := atomic.LoadInt32(&fullSyncs); c != 2 {
+		t.Errorf("sync should be called 2 times, but was called %d times", c)
+	}
+
+	q.Shutdown()
+}
+
+func TestStoreMax(t *testing.T) {
+	var v int64 = 10
+
+	storeMax(&v, 5)
+	if v != 10 {
+		t.Errorf("expected 10 but returned %d", v)
+	}
+
+	storeMax(&v, 20)
+	if v != 20 {
+		t.Errorf("expected 20 but returned %d", v)
+	}
+}
+
+func TestLen(t *testing.T) {
+	q := NewCustomTaskQueue(mockSynFn, func(obj interface{}) (interface{}, error) {
+		return obj, nil
+	})
+	if q.Len() != 0 {
+		t.Errorf("expected an empty queue but returned %v", q.Len())
+	}
+
+	before := time.Now().UnixNano()
+	q.EnqueueTask(mockEnqueueObj{k: "key1"})
+	q.EnqueueTask(mockEnqueueObj{k: "key2"})
+	if q.Len() != 2 {
+		t.Errorf("expected 2 elements but returned %v", q.Len())
+	}
+
+	item, quit := q.queue.Get()
+	if quit {
+		t.Fatalf("unexpected shutdown of the queue")
+	}
+	if enqueued := item.(Element).Enqueued; enqueued < before || enqueued > time.Now().UnixNano() {
+		t.Errorf("unexpected enqueue time %v", enqueued)
+	}
+}
+
+func TestIsNonRetryableError(t *testing.T) {
+	if IsNonRetryableError(fmt.Errorf("error")) {
+		t.Errorf("expected a retryable error")
+	}
+
+	err := NewNonRetryableError(fmt.Errorf("error"))
+	if !IsNonRetryableError(err) {
+		t.Errorf("expected a non retryable error")
+	}
+	if !IsNonRetryableError(fmt.Errorf("wrapped: %w", err)) {
+		t.Errorf("expected a wrapped non retryable error")
+	}
+	if NewNonRetryableError(nil) != nil {
+		t.Errorf("expected nil error")
+	}
+}
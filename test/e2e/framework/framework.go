@@ -143,7 +143,7 @@ func (f *Framework) AfterEach() {
 	ginkgo.By("Dumping NGINX configuration after failure")
 	Logf("%v", o)
 
-	log, err := f.NginxLogs()
+	log, err := DumpPodLogs(f.KubeClientSet, f.Namespace, f.pod.Name, "")
 	if err != nil {
 		Logf("Unexpected error obtaining NGINX logs: %v", err)
 		return
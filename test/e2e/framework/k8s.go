@@ -166,6 +166,7 @@ func waitForPodsDeleted(kubeClientSet kubernetes.Interface, timeout time.Duratio
 }
 
 // WaitForEndpoints waits for a given amount of time until the number of endpoints = expectedEndpoints.
+// Transient errors of the API server are retried until the timeout.
 func WaitForEndpoints(kubeClientSet kubernetes.Interface, timeout time.Duration, name, ns string, expectedEndpoints int) error {
 	if expectedEndpoints == 0 {
 		return nil
@@ -173,11 +174,13 @@ func WaitForEndpoints(kubeClientSet kubernetes.Interface, timeout time.Duration,
 
 	return wait.PollImmediate(Poll, timeout, func() (bool, error) {
 		endpoint, err := kubeClientSet.CoreV1().Endpoints(ns).Get(context.TODO(), name, metav1.GetOptions{})
-		if k8sErrors.IsNotFound(err) {
+		if k8sErrors.IsNotFound(err) || isRetryableAPIError(err) {
 			return false, nil
 		}
 
-		assert.Nil(ginkgo.GinkgoT(), err, "getting endpoints")
+		if err != nil {
+			return false, fmt.Errorf("getting endpoints: %v", err)
+		}
 
 		if countReadyEndpoints(endpoint) == expectedEndpoints {
 			return true, nil
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWaitForEndpointsTransientError(t *testing.T) {
+	client := fake.NewSimpleClientset(&core.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "echo",
+			Namespace: "default",
+		},
+		Subsets: []core.EndpointSubset{
+			{Addresses: []core.EndpointAddress{{IP: "10.0.0.1"}}},
+		},
+	})
+
+	calls := 0
+	client.PrependReactor("get", "endpoints", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if calls == 1 {
+			return true, nil, k8sErrors.NewInternalError(fmt.Errorf("etcdserver: leader changed"))
+		}
+
+		return false, nil, nil
+	})
+
+	if err := WaitForEndpoints(client, 10*time.Second, "echo", "default", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected two requests but returned %v", calls)
+	}
+}
+
+func TestWaitForEndpointsError(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("get", "endpoints", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8sErrors.NewForbidden(core.Resource("endpoints"), "echo", fmt.Errorf("not allowed"))
+	})
+
+	if err := WaitForEndpoints(client, 10*time.Second, "echo", "default", 1); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -18,7 +18,11 @@ package framework
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -37,3 +41,60 @@ func Logs(client kubernetes.Interface, namespace, podName string) (string, error
 
 	return string(logs), nil
 }
+
+// DumpPodLogs returns the log entries of the containers of a given Pod,
+// each line prefixed with the pod and container names. All the containers
+// are dumped if container is empty. Containers not started yet, or whose
+// logs cannot be fetched, are skipped with a note.
+func DumpPodLogs(client kubernetes.Interface, namespace, podName, container string) (string, error) {
+	pod, err := client.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	containers := []string{}
+	for _, c := range pod.Spec.Containers {
+		if container == "" || c.Name == container {
+			containers = append(containers, c.Name)
+		}
+	}
+
+	if len(containers) == 0 {
+		return "", fmt.Errorf("container %v not found in pod %v/%v", container, namespace, podName)
+	}
+
+	var out strings.Builder
+	for _, name := range containers {
+		prefix := fmt.Sprintf("[%v/%v] ", podName, name)
+
+		if !containerStarted(pod, name) {
+			out.WriteString(prefix + "container not started, skipping logs\n")
+			continue
+		}
+
+		logs, err := client.CoreV1().Pods(namespace).GetLogs(podName, &core.PodLogOptions{
+			Container: name,
+		}).Do(context.TODO()).Raw()
+		if err != nil {
+			out.WriteString(fmt.Sprintf("%verror fetching logs: %v\n", prefix, err))
+			continue
+		}
+
+		for _, line := range strings.Split(strings.TrimSuffix(string(logs), "\n"), "\n") {
+			out.WriteString(prefix + line + "\n")
+		}
+	}
+
+	return out.String(), nil
+}
+
+// containerStarted checks if the container of a pod is running or has run
+func containerStarted(pod *core.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.State.Running != nil || status.State.Terminated != nil
+		}
+	}
+
+	return false
+}
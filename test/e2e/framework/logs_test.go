@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func buildMultiContainerPod() *core.Pod {
+	return &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-nginx",
+			Namespace: "default",
+		},
+		Spec: core.PodSpec{
+			Containers: []core.Container{
+				{Name: "controller"},
+				{Name: "sidecar"},
+			},
+		},
+		Status: core.PodStatus{
+			ContainerStatuses: []core.ContainerStatus{
+				{
+					Name:  "controller",
+					State: core.ContainerState{Running: &core.ContainerStateRunning{}},
+				},
+				{
+					Name:  "sidecar",
+					State: core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "ContainerCreating"}},
+				},
+			},
+		},
+	}
+}
+
+func TestDumpPodLogs(t *testing.T) {
+	client := fake.NewSimpleClientset(buildMultiContainerPod())
+
+	testCases := map[string]struct {
+		container string
+		expected  string
+	}{
+		"all containers": {
+			container: "",
+			expected: "[ingress-nginx/controller] fake logs\n" +
+				"[ingress-nginx/sidecar] container not started, skipping logs\n",
+		},
+		"single container": {
+			container: "controller",
+			expected:  "[ingress-nginx/controller] fake logs\n",
+		},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			logs, err := DumpPodLogs(client, "default", "ingress-nginx", tc.container)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if logs != tc.expected {
+				t.Errorf("returned %q but expected %q", logs, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDumpPodLogsUnknownContainer(t *testing.T) {
+	client := fake.NewSimpleClientset(buildMultiContainerPod())
+
+	if _, err := DumpPodLogs(client, "default", "ingress-nginx", "unknown"); err == nil {
+		t.Errorf("expected an error for an unknown container")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/onsi/ginkgo"
@@ -39,6 +40,10 @@ const (
 
 	// DefaultTimeout time to wait for operations to complete
 	DefaultTimeout = 5 * time.Minute
+
+	// observedGenerationAnnotation contains the generation of the ingress
+	// observed by the ingress controller in the last status update
+	observedGenerationAnnotation = "nginx.ingress.kubernetes.io/status-observed-generation"
 )
 
 func nowStamp() string {
@@ -260,6 +265,57 @@ func ingressInNamespace(c kubernetes.Interface, namespace, name string) wait.Con
 	}
 }
 
+// WaitForIngressGeneration waits until the ingress controller records the
+// observation of a particular generation (or a newer one) of an ingress object
+func WaitForIngressGeneration(c kubernetes.Interface, namespace, name string, generation int64, timeout time.Duration) error {
+	return wait.Poll(Poll, timeout, ingressGenerationObserved(c, namespace, name, generation))
+}
+
+func ingressGenerationObserved(c kubernetes.Interface, namespace, name string, generation int64) wait.ConditionFunc {
+	return func() (bool, error) {
+		ing, err := c.NetworkingV1beta1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		value, ok := ing.Annotations[observedGenerationAnnotation]
+		if !ok {
+			return false, nil
+		}
+
+		observed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid observed generation %q in ingress %v/%v", value, namespace, name)
+		}
+
+		return observed >= generation, nil
+	}
+}
+
+// WaitForIngressAnnotation waits until an annotation of an ingress object
+// has a particular value
+func WaitForIngressAnnotation(c kubernetes.Interface, namespace, name, key, value string, timeout time.Duration) error {
+	return wait.PollImmediate(Poll, timeout, ingressAnnotationEqual(c, namespace, name, key, value))
+}
+
+func ingressAnnotationEqual(c kubernetes.Interface, namespace, name, key, value string) wait.ConditionFunc {
+	return func() (bool, error) {
+		ing, err := c.NetworkingV1beta1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || isRetryableAPIError(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		current, ok := ing.Annotations[key]
+		return ok && current == value, nil
+	}
+}
+
 func podRunning(c kubernetes.Interface, podName, namespace string) wait.ConditionFunc {
 	return func() (bool, error) {
 		pod, err := c.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
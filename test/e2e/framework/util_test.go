@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	networking "k8s.io/api/networking/v1beta1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWaitForIngressAnnotation(t *testing.T) {
+	client := fake.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"example.com/owner": "old",
+			},
+		},
+	})
+
+	calls := 0
+	client.PrependReactor("get", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		switch calls {
+		case 1:
+			return true, nil, k8sErrors.NewInternalError(fmt.Errorf("etcdserver: leader changed"))
+		case 2:
+			return false, nil, nil
+		default:
+			return true, &networking.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"example.com/owner": "new",
+					},
+				},
+			}, nil
+		}
+	})
+
+	if err := WaitForIngressAnnotation(client, "default", "foo", "example.com/owner", "new", 10*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected three requests but returned %v", calls)
+	}
+}
+
+func TestWaitForIngressAnnotationError(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("get", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8sErrors.NewForbidden(networking.Resource("ingresses"), "foo", fmt.Errorf("not allowed"))
+	})
+
+	if err := WaitForIngressAnnotation(client, "default", "foo", "example.com/owner", "new", 10*time.Second); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestIngressGenerationObserved(t *testing.T) {
+	buildIngress := func(annotations map[string]string) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Generation:  3,
+				Annotations: annotations,
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		ing         *networking.Ingress
+		observed    bool
+		errExpected bool
+	}{
+		"missing ingress":    {nil, false, false},
+		"missing annotation": {buildIngress(nil), false, false},
+		"older generation":   {buildIngress(map[string]string{observedGenerationAnnotation: "2"}), false, false},
+		"same generation":    {buildIngress(map[string]string{observedGenerationAnnotation: "3"}), true, false},
+		"newer generation":   {buildIngress(map[string]string{observedGenerationAnnotation: "4"}), true, false},
+		"invalid annotation": {buildIngress(map[string]string{observedGenerationAnnotation: "three"}), false, true},
+	}
+
+	for title, tc := range testCases {
+		t.Run(title, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if tc.ing != nil {
+				client = fake.NewSimpleClientset(tc.ing)
+			}
+
+			observed, err := ingressGenerationObserved(client, "default", "foo", 3)()
+			if (err != nil) != tc.errExpected {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if observed != tc.observed {
+				t.Errorf("returned %v but expected %v", observed, tc.observed)
+			}
+		})
+	}
+}
+
+func TestWaitForIngressGenerationTimeout(t *testing.T) {
+	client := fake.NewSimpleClientset(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "default",
+			Generation: 2,
+			Annotations: map[string]string{
+				observedGenerationAnnotation: "1",
+			},
+		},
+	})
+
+	if err := WaitForIngressGeneration(client, "default", "foo", 2, 100*time.Millisecond); err != wait.ErrWaitTimeout {
+		t.Errorf("returned %v but expected %v", err, wait.ErrWaitTimeout)
+	}
+}